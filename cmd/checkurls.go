@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal"
+	"github.com/spf13/cobra"
+)
+
+// newCheckURLsCmd returns the `check-urls` command, which reports URL and alias collisions.
+func newCheckURLsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "check-urls",
+		Short: "Report pages sharing a URL and conflicting or dangling aliases",
+		Long: "Compute each page's URL from its path, slug, url and the configured permalinks, plus its aliases,\n" +
+			"and report duplicate URLs, aliases that shadow a page and aliases of unpublished pages. Exits 1 if any are found.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, fsys, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			defer closeSource(fsys)
+			issues, err := internal.CheckURLs(cfg, fsys)
+			if err != nil {
+				return err
+			}
+			if len(issues) > 0 {
+				// Exiting skips the deferred close
+				closeSource(fsys)
+				exitFunc(1)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal"
+	"github.com/spf13/cobra"
+)
+
+// newCoerceCmd returns the coerce command, which converts fields to a
+// target type through the normal pipeline.
+func newCoerceCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "coerce <field=type>...",
+		Short: "Convert fields to list, bool, int, float, date or string, reporting values that cannot be converted",
+		Long: "Convert the values of each field to a type across the files matching --if, e.g. tags: go to\n" +
+			"tags: [go], draft: \"true\" to draft: true and weight: \"5\" to weight: 5. Fields may be dotted\n" +
+			"paths such as params.rating. Values that cannot be converted are reported and left as they are.",
+		Example: "  hugo-frontmatter-toolbox coerce tags=list categories=list\n" +
+			"  hugo-frontmatter-toolbox coerce draft=bool weight=int --dry-run",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, fsys, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			defer closeSource(fsys)
+			cfg.Coerce = map[string]string{}
+			for _, arg := range args {
+				field, typ, ok := strings.Cut(arg, "=")
+				typ = strings.ToLower(strings.TrimSpace(typ))
+				if !ok || strings.TrimSpace(field) == "" {
+					return fmt.Errorf("invalid argument %q: use field=type", arg)
+				}
+				if !slices.Contains(internal.CoerceTypes, typ) {
+					return fmt.Errorf("invalid type %q for %s: use %s", typ, field, strings.Join(internal.CoerceTypes, ", "))
+				}
+				cfg.Coerce[strings.TrimSpace(field)] = typ
+			}
+			_, err = internal.RunToolFS(cfg, fsys)
+			return err
+		},
+	}
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal"
+	"github.com/spf13/cobra"
+)
+
+// dateLayouts maps the names accepted by --layout to Go time layouts.
+var dateLayouts = map[string]string{
+	"rfc3339":  time.RFC3339,
+	"date":     "2006-01-02",
+	"datetime": "2006-01-02T15:04:05",
+}
+
+// newNormalizeDatesCmd returns the normalize-dates command, which rewrites
+// date fields in one layout and time zone through the normal pipeline.
+func newNormalizeDatesCmd() *cobra.Command {
+	var layout, tz, fields string
+	cmd := &cobra.Command{
+		Use:   "normalize-dates",
+		Short: "Rewrite date fields in one layout and time zone, reporting values that cannot be parsed",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, fsys, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			defer closeSource(fsys)
+			cfg.DateLayout = layout
+			if l, ok := dateLayouts[strings.ToLower(layout)]; ok {
+				cfg.DateLayout = l
+			} else if !strings.Contains(layout, "2006") {
+				return fmt.Errorf("invalid --layout %q: use rfc3339, date, datetime or a Go layout with a year", layout)
+			}
+			if tz == "" && cfg.Site != nil {
+				tz = cfg.Site.TimeZone
+			}
+			if tz != "" {
+				if cfg.DateLocation, err = time.LoadLocation(tz); err != nil {
+					return fmt.Errorf("invalid --tz: %v", err)
+				}
+			}
+			cfg.DateKeys = parseCSV(fields)
+			_, err = internal.RunToolFS(cfg, fsys)
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&layout, "layout", "rfc3339", "Date layout: rfc3339, date, datetime or a Go layout such as \"2006-01-02 15:04\"")
+	cmd.Flags().StringVar(&tz, "tz", "", "Time zone to rewrite dates in, e.g. Europe/London (defaults to timeZone from the Hugo site configuration, else UTC)")
+	cmd.Flags().StringVar(&fields, "fields", "", "Comma-separated fields to rewrite (defaults to the site's frontmatter date fields)")
+	return cmd
+}
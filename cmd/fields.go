@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal"
+	"github.com/spf13/cobra"
+)
+
+// newFieldsCmd returns the `fields` command, which inventories the frontmatter keys in use.
+func newFieldsCmd() *cobra.Command {
+	var asSchema bool
+	cmd := &cobra.Command{
+		Use:   "fields",
+		Short: "List every frontmatter key with its types, file count and samples, and report type drift",
+		Long: "Walk the files matching --if and list every key, including nested paths such as params.author and\n" +
+			"resources[].src, with the value types seen, the number of files using it and sample values. Keys\n" +
+			"whose type differs between files are reported. With --schema, print the inferred JSON Schema instead.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, fsys, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			defer closeSource(fsys)
+			_, err = internal.RunFields(cfg, fsys, asSchema, os.Stdout)
+			return err
+		},
+	}
+	cmd.Flags().BoolVar(&asSchema, "schema", false, "Print a JSON Schema inferred from the files; keys in every file are required")
+	return cmd
+}
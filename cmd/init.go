@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal"
+	"github.com/spf13/cobra"
+)
+
+// newInitCmd returns the init command, which adds frontmatter to content
+// files that have none.
+func newInitCmd() *cobra.Command {
+	opts := internal.InitOptions{}
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Find content files without frontmatter and add a block, seeded from a template or derived values",
+		Long: "Find the content files that have no frontmatter and add a block in --format. The block holds the\n" +
+			"frontmatter of --template, rendered like an archetype, and the fields named by --derive: the title\n" +
+			"from the first heading, or else the file name, and the date the file was added to git, or else its\n" +
+			"modification time. --if is evaluated against the new frontmatter. Use --list to only list the files.",
+		Example: "  hugo-frontmatter-toolbox init --list\n" +
+			"  hugo-frontmatter-toolbox init --format toml --template archetypes/default.md --dry-run",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, fsys, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			defer closeSource(fsys)
+			_, err = internal.RunInit(cfg, fsys, opts)
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&opts.Format, "format", "yaml", "Frontmatter format to add: yaml, toml or json")
+	cmd.Flags().StringVar(&opts.Template, "template", "", "File whose frontmatter seeds every block, e.g. an archetype")
+	cmd.Flags().StringSliceVar(&opts.Derive, "derive", []string{"title", "date"}, "Fields to derive from each file: title, date")
+	cmd.Flags().StringVar(&opts.DateFrom, "date-from", "git", "Source of derived dates: git, falling back to mtime, or mtime")
+	cmd.Flags().BoolVar(&opts.List, "list", false, "Only list the files without frontmatter")
+	return cmd
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal"
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/helpers"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// newLifecycleCmds returns the publishing lifecycle commands: publish,
+// schedule, expire, archive and status. They honour --if and the global
+// flags such as --dry-run, --yes and --gc.
+func newLifecycleCmds() []*cobra.Command {
+	var at, olderThan, archiveTo string
+
+	// lifecycleCmd returns a command running the lifecycle operation op through the normal pipeline.
+	lifecycleCmd := func(op, short string, prepare func(*config.Config) error) *cobra.Command {
+		return &cobra.Command{
+			Use:   op,
+			Short: short,
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				cfg, fsys, err := loadConfig(cmd)
+				if err != nil {
+					return err
+				}
+				defer closeSource(fsys)
+				cfg.Lifecycle = op
+				if prepare != nil {
+					if err := prepare(&cfg); err != nil {
+						return err
+					}
+				}
+				_, err = internal.RunToolFS(cfg, fsys)
+				return err
+			},
+		}
+	}
+
+	publish := lifecycleCmd("publish", "Clear draft and set publishDate to now where unset", nil)
+
+	schedule := lifecycleCmd("schedule", "Set publishDate to --at and clear draft", func(cfg *config.Config) error {
+		t, ok := helpers.ParseDate(at)
+		if !ok {
+			return fmt.Errorf("invalid --at date %q", at)
+		}
+		cfg.ScheduleAt = t
+		return nil
+	})
+	schedule.Flags().StringVar(&at, "at", "", "Publish date, e.g. 2025-01-31 or 2025-01-31T09:00:00+01:00")
+	_ = schedule.MarkFlagRequired("at")
+
+	expire := lifecycleCmd("expire", "Set expiryDate to now on pages dated before --older-than", func(cfg *config.Config) error {
+		// An age is an offset back from now, whichever sign it is given with
+		offset := "-" + strings.TrimLeft(strings.TrimSpace(olderThan), "+-")
+		if _, err := helpers.ShiftDate(cfg.Now, offset); err != nil {
+			return fmt.Errorf("invalid --older-than: %v", err)
+		}
+		cfg.OlderThan = offset
+		return nil
+	})
+	expire.Flags().StringVar(&olderThan, "older-than", "", "Age of the pages to expire, e.g. 2y, 6m or 90d")
+	_ = expire.MarkFlagRequired("older-than")
+
+	archive := &cobra.Command{
+		Use:   "archive",
+		Short: "Move pages matching --if into an archive section, keeping their old URL as an alias",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, fsys, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			defer closeSource(fsys)
+			_, err = internal.RunArchive(cfg, fsys, archiveTo)
+			return err
+		},
+	}
+	archive.Flags().StringVar(&archiveTo, "to", "archive", "Section to move pages into")
+
+	status := &cobra.Command{
+		Use:   "status",
+		Short: "List drafts, future-scheduled and expired pages as of --now",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, fsys, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			defer closeSource(fsys)
+			_, err = internal.RunStatus(cfg, fsys)
+			return err
+		},
+	}
+
+	return []*cobra.Command{publish, schedule, expire, archive, status}
+}
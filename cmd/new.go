@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal"
+	"github.com/spf13/cobra"
+)
+
+// newNewCmd returns the `new` command, which creates content from the site's archetypes.
+func newNewCmd() *cobra.Command {
+	var kind string
+	cmd := &cobra.Command{
+		Use:   "new <path>",
+		Short: "Create a content file from its section's archetype",
+		Long: "Create a content file from archetypes/<section>.md, falling back to archetypes/default.md,\n" +
+			"filling in placeholders such as {{ .Name }} and {{ .Date }}. The path is relative to the content directory.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, fsys, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			defer closeSource(fsys)
+			created := cfg.Now
+			if created.IsZero() {
+				created = time.Now()
+			}
+			_, err = internal.NewContent(cfg, fsys, args[0], kind, created)
+			return err
+		},
+	}
+	cmd.Flags().StringVarP(&kind, "kind", "k", "", "Archetype to use instead of the section's")
+	return cmd
+}
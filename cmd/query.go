@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal"
+	"github.com/spf13/cobra"
+)
+
+// newQueryCmd returns the `query` command, which evaluates a jq-like expression over each page's frontmatter.
+func newQueryCmd() *cobra.Command {
+	var opts internal.QueryOptions
+	cmd := &cobra.Command{
+		Use:   "query <expression>",
+		Short: "Evaluate a jq or JSONPath-style expression against the frontmatter of each page",
+		Long: "Evaluate an expression against the frontmatter of each page matching --if and print its results\n" +
+			"per file. Paths such as .params.author, resources[].src and $.tags[0] read nested values, and\n" +
+			"$file, $section and $body hold the page's path, section and body. Expressions combine paths with\n" +
+			"|, ==, !=, <, >, and, or, [...] and the functions select, map, any, all, length, keys, has,\n" +
+			"contains, test, startswith, endswith, not, type and empty. Missing keys read as null. With\n" +
+			"--files, only the pages for which the expression is true are listed.",
+		Example: "  hugo-frontmatter-toolbox query '.params.author'\n" +
+			"  hugo-frontmatter-toolbox query --files 'any(resources[]; .params.credits == null or .params.credits == \"\")'\n" +
+			"  hugo-frontmatter-toolbox query '[.tags[] | select(test(\"^go\"))]' --format json",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, fsys, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			defer closeSource(fsys)
+			return internal.RunQuery(cfg, fsys, args[0], opts, os.Stdout)
+		},
+	}
+	cmd.Flags().BoolVar(&opts.Files, "files", false, "Only list the pages for which the expression yields a value other than null or false")
+	cmd.Flags().StringVar(&opts.Format, "format", "plain", "Output format: plain or json")
+	return cmd
+}
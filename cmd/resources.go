@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal"
+	"github.com/spf13/cobra"
+)
+
+// newResourcesCmd returns the `resources` command, which lists and checks page bundle resources.
+func newResourcesCmd() *cobra.Command {
+	var addMissing bool
+	cmd := &cobra.Command{
+		Use:   "resources",
+		Short: "List page bundle files and check resources metadata and image references",
+		Long: "List the files of each leaf bundle matching --if, marking images no resources entry covers, and report\n" +
+			"resources entries whose src matches no file and featured_image/images values naming files found neither\n" +
+			"in the bundle nor in a static directory. Exits 1 if any are found. With --add-missing, add a resources\n" +
+			"entry for each uncovered image, with the usual diff and confirmation.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, fsys, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			defer closeSource(fsys)
+			issues, err := internal.CheckResources(cfg, fsys)
+			if err != nil {
+				return err
+			}
+			if addMissing {
+				cfg.AddResources = true
+				if _, err := internal.RunToolFS(cfg, fsys); err != nil {
+					return err
+				}
+			}
+			if len(issues) > 0 {
+				// Exiting skips the deferred close
+				closeSource(fsys)
+				exitFunc(1)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&addMissing, "add-missing", false, "Add resources entries for bundle images that have none")
+	return cmd
+}
@@ -2,12 +2,21 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/Daviey/hugo-frontmatter-toolbox/internal"
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/helpers"
+	reportpkg "github.com/Daviey/hugo-frontmatter-toolbox/internal/report"
 	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/site"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/vfs"
 	"github.com/spf13/cobra"
 )
 
@@ -17,6 +26,8 @@ var (
 	condition     string
 	dryRun        bool
 	report        bool
+	reportFormat  string
+	reportFile    string
 	diffContext   int
 	lint          bool
 	fix           bool
@@ -27,41 +38,59 @@ var (
 	yes           bool
 	extractKey    string
 	extractFormat string
+	jobs          int
+	source        string
+	stdin         bool
+	sharedStr     string
+	allTrans      bool
+	untranslated  bool
+	effective     bool
+	archetypes    bool
+	addAliases    bool
+	nowStr        string
+	keepGoing     bool
 	version       = "v1.0.0"
 	exitFunc      = os.Exit // 👈 overrideable for tests
 )
 
+// exitNoMatch is the exit status used by --stdin when the document does not match --if.
+const exitNoMatch = 2
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
 	rootCmd := &cobra.Command{
 		Use:   "hugo-frontmatter-toolbox",
 		Short: "Batch edit Hugo frontmatter (YAML, TOML, JSON)",
+		// Errors are printed once by Execute
+		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return internal.RunTool(config.Config{
-				ContentDir:       contentDir,
-				SetField:         setField,
-				Condition:        condition,
-				DryRun:           dryRun,
-				Report:           report,
-				DiffContext:      diffContext,
-				Lint:             lint,
-				Fix:              fix,
-				RequiredFields:   parseCSV(requiredStr),
-				ProhibitedFields: parseCSV(prohibitedStr),
-				GitCommit:        gitCommit,
-				GcMsg:            gcMsg,
-				Yes:              yes,
-				ExtractKey:       extractKey,
-				ExtractFormat:    extractFormat,
-			})
+			cfg, fsys, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			defer closeSource(fsys)
+			if stdin {
+				err := internal.RunFilter(cfg, os.Stdin, os.Stdout)
+				if errors.Is(err, internal.ErrNoMatch) {
+					// Exiting skips the deferred close
+					closeSource(fsys)
+					exitFunc(exitNoMatch)
+					return nil
+				}
+				return err
+			}
+			_, err = internal.RunToolFS(cfg, fsys)
+			return err
 		},
 	}
 
-	rootCmd.PersistentFlags().StringVarP(&contentDir, "content-dir", "c", "content", "Path to Hugo content directory")
+	rootCmd.PersistentFlags().StringVarP(&contentDir, "content-dir", "c", "content", "Path to Hugo content directory (defaults to contentDir from the Hugo site configuration)")
 	rootCmd.PersistentFlags().StringVarP(&setField, "set", "s", "", "Set frontmatter field, e.g. draft=true")
-	rootCmd.PersistentFlags().StringVarP(&condition, "if", "i", "", "Condition, e.g. date<2023-01-01 AND draft=false")
+	rootCmd.PersistentFlags().StringVarP(&condition, "if", "i", "", "Condition, e.g. date<now-1y AND draft=false or lastmod within 30d")
 	rootCmd.PersistentFlags().BoolVarP(&dryRun, "dry-run", "n", false, "Show diff but don't write changes")
 	rootCmd.PersistentFlags().BoolVar(&report, "report", false, "Show report summary after execution")
+	rootCmd.PersistentFlags().StringVar(&reportFormat, "report-format", "text", "Report format: text, json, markdown or html (implies --report; defaults to the --report-file extension)")
+	rootCmd.PersistentFlags().StringVar(&reportFile, "report-file", "", "Write the report to this file instead of stdout (implies --report)")
 	rootCmd.PersistentFlags().BoolVar(&lint, "lint", false, "Lint for required/prohibited fields")
 	rootCmd.PersistentFlags().BoolVar(&fix, "fix", false, "Fix linting issues (add/remove fields)")
 	rootCmd.PersistentFlags().StringVar(&requiredStr, "required", "", "Comma-separated required fields")
@@ -70,12 +99,30 @@ func Execute() {
 	rootCmd.PersistentFlags().StringVar(&gcMsg, "gc-msg", "", "Override commit message for --gc")
 	rootCmd.PersistentFlags().IntVar(&diffContext, "diff-context", 2, "Lines of unchanged context around diffs")
 	rootCmd.PersistentFlags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation prompts and proceed with changes")
-	rootCmd.PersistentFlags().StringVar(&extractKey, "extract", "", "Extract value of specified frontmatter key across all files")
-	rootCmd.PersistentFlags().StringVar(&extractFormat, "extract-format", "plain", "Output format for --extract: plain, csv, or json")
+	rootCmd.PersistentFlags().StringVar(&extractKey, "extract", "", "Extract comma-separated frontmatter keys from files matching --if, e.g. title,date,tags,params.author")
+	rootCmd.PersistentFlags().StringVar(&extractFormat, "extract-format", "plain", "Output format for --extract: plain, table, csv, json, jsonl or yaml")
+	rootCmd.PersistentFlags().IntVarP(&jobs, "jobs", "j", 0, "Number of files to process in parallel (0 uses all CPUs)")
+	rootCmd.PersistentFlags().StringVar(&source, "source", "", "Read content from an archive (.zip, .tar, .tar.gz) or a git revision (git:REV) instead of the working copy")
+	rootCmd.PersistentFlags().StringVar(&sharedStr, "shared", "", "Comma-separated fields every translation of a page must share, e.g. date,weight,translationKey (lint)")
+	rootCmd.PersistentFlags().BoolVar(&allTrans, "all-translations", false, "Apply changes to every translation of a page matching --if")
+	rootCmd.PersistentFlags().BoolVar(&untranslated, "untranslated", false, "Report pages missing a translation in each configured language")
+	rootCmd.PersistentFlags().BoolVar(&effective, "effective", false, "Evaluate --if, lint and --extract against frontmatter with section cascades and Hugo date fields applied")
+	rootCmd.PersistentFlags().BoolVar(&archetypes, "archetype", false, "Lint pages for fields their section's archetype defines (with --lint)")
+	rootCmd.PersistentFlags().BoolVar(&addAliases, "add-aliases", false, "Add a page's old URL to its aliases when a change moves it, e.g. a new slug")
+	rootCmd.PersistentFlags().StringVar(&nowStr, "now", "", "Reference time for relative dates in --if, lifecycle commands and status instead of the current time, e.g. 2025-01-01")
+	// The last of --keep-going and --fail-fast wins, so either can override an alias
+	keepGoing = false
+	rootCmd.PersistentFlags().Var(switchFlag{&keepGoing, true}, "keep-going", "Record files that cannot be read, parsed or written, process the rest, then list the errors and exit non-zero")
+	rootCmd.PersistentFlags().Var(switchFlag{&keepGoing, false}, "fail-fast", "Stop at the first file that cannot be read, parsed or written (the default; overrides an earlier --keep-going)")
+	rootCmd.PersistentFlags().Lookup("keep-going").NoOptDefVal = "true"
+	rootCmd.PersistentFlags().Lookup("fail-fast").NoOptDefVal = "true"
 	rootCmd.PersistentFlags().Bool("version", false, "Print version info")
 
 	// PersistentPreRun is executed before any command and is used to display help or version information.
 	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		// The arguments parsed, so errors from here on are not usage errors
+		// and the usage text would only bury them, e.g. the files --keep-going lists
+		cmd.SilenceUsage = true
 		if len(os.Args) == 1 {
 			_ = cmd.Help()
 			exitFunc(0)
@@ -86,12 +133,134 @@ func Execute() {
 		}
 	}
 
+	rootCmd.AddCommand(newNewCmd(), newCheckURLsCmd())
+	rootCmd.AddCommand(newLifecycleCmds()...)
+	rootCmd.AddCommand(newNormalizeDatesCmd(), newResourcesCmd())
+	rootCmd.AddCommand(newExportCmd(), newImportCmd(), newStatsCmd(), newFieldsCmd(), newCoerceCmd(), newInitCmd())
+	rootCmd.AddCommand(newQueryCmd())
+
+	rootCmd.Flags().BoolVar(&stdin, "stdin", false, "Read a single document from stdin and write the result to stdout (exits 2 if --if does not match)")
+
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
+		fmt.Fprintln(os.Stderr, "Error:", err)
 		exitFunc(1)
 	}
 }
 
+// newConfig builds the run configuration from the parsed flags.
+func newConfig() config.Config {
+	return config.Config{
+		ContentDir:       contentDir,
+		SetField:         setField,
+		Condition:        condition,
+		DryRun:           dryRun,
+		Report:           report,
+		ReportFormat:     reportFormat,
+		ReportFile:       reportFile,
+		DiffContext:      diffContext,
+		Lint:             lint,
+		Fix:              fix,
+		RequiredFields:   parseCSV(requiredStr),
+		ProhibitedFields: parseCSV(prohibitedStr),
+		GitCommit:        gitCommit,
+		GcMsg:            gcMsg,
+		Yes:              yes,
+		ExtractKeys:      parseCSV(extractKey),
+		ExtractFormat:    extractFormat,
+		Jobs:             jobs,
+		Source:           source,
+		SharedFields:     parseCSV(sharedStr),
+		AllTranslations:  allTrans,
+		Untranslated:     untranslated,
+		Effective:        effective,
+		LintArchetypes:   archetypes,
+		AddAliases:       addAliases,
+		KeepGoing:        keepGoing,
+	}
+}
+
+// switchFlag is a boolean flag that sets target to value when given. Flags
+// sharing a target switch it in command-line order, so the last one wins.
+type switchFlag struct {
+	target *bool
+	value  bool
+}
+
+// String is "false" so that help shows no default: the default is in the
+// usage text.
+func (f switchFlag) String() string { return "false" }
+func (f switchFlag) Type() string   { return "bool" }
+func (f switchFlag) Set(s string) error {
+	on, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	if on {
+		*f.target = f.value
+	}
+	return nil
+}
+
+// closeSource releases the content source opened by loadConfig, such as the
+// git process reading a git:REV source.
+func closeSource(fsys vfs.FS) {
+	if c, ok := fsys.(io.Closer); ok {
+		_ = c.Close()
+	}
+}
+
+// loadConfig builds the run configuration from the parsed flags, opens the
+// content source and fills in defaults from the Hugo site configuration found
+// in it. Flags given explicitly on the command line take precedence. Callers
+// must closeSource the returned FS.
+func loadConfig(cmd *cobra.Command) (config.Config, vfs.FS, error) {
+	cfg := newConfig()
+	if nowStr != "" {
+		t, ok := helpers.ParseDate(nowStr)
+		if !ok {
+			return cfg, nil, fmt.Errorf("invalid --now date %q", nowStr)
+		}
+		cfg.Now = t
+	}
+	if cmd.Flags().Changed("report-format") || reportFile != "" {
+		cfg.Report = true
+	}
+	if !cmd.Flags().Changed("report-format") {
+		// Without --report-format, the report file's extension picks the format
+		switch strings.ToLower(filepath.Ext(reportFile)) {
+		case ".json":
+			cfg.ReportFormat = "json"
+		case ".md", ".markdown":
+			cfg.ReportFormat = "markdown"
+		case ".html", ".htm":
+			cfg.ReportFormat = "html"
+		}
+	}
+	if !slices.Contains(reportpkg.Formats, cfg.ReportFormat) {
+		return cfg, nil, fmt.Errorf("invalid --report-format %q: use %s", cfg.ReportFormat, strings.Join(reportpkg.Formats, ", "))
+	}
+	fsys, err := vfs.Open(cfg.Source)
+	if err != nil {
+		return cfg, nil, err
+	}
+
+	s, err := site.Load(fsys, ".")
+	if errors.Is(err, site.ErrNotFound) {
+		return cfg, fsys, nil
+	}
+	if err != nil {
+		closeSource(fsys)
+		return cfg, nil, err
+	}
+	cfg.Site = s
+	if !cmd.Flags().Changed("content-dir") {
+		dirs := s.ContentDirs()
+		cfg.ContentDir = dirs[0]
+		cfg.ContentDirs = dirs[1:]
+	}
+	return cfg, fsys, nil
+}
+
 func parseCSV(input string) []string {
 	if input == "" {
 		return nil
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -82,3 +83,129 @@ func TestExecute_Version(t *testing.T) {
 		t.Errorf("Expected version output, got: %s", output)
 	}
 }
+
+// runInDir runs the command line args in dir with the files given, and
+// returns its output and exit status.
+func runInDir(t *testing.T, files map[string]string, args ...string) (dir, output string, code int) {
+	t.Helper()
+	dir = t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+
+	exitFunc = func(code int) { panic(fmt.Sprintf("exit %d", code)) }
+	defer func() { exitFunc = os.Exit }()
+	output = captureOutput(func() {
+		defer func() {
+			if r := recover(); r != nil {
+				if _, err := fmt.Sscanf(fmt.Sprint(r), "exit %d", &code); err != nil {
+					panic(r)
+				}
+			}
+		}()
+		os.Args = append([]string{"hugo-frontmatter-toolbox"}, args...)
+		Execute()
+	})
+	return dir, output, code
+}
+
+// TestExecute_ExpireOlderThan tests that --older-than accepts an age with either sign.
+func TestExecute_ExpireOlderThan(t *testing.T) {
+	for _, age := range []string{"2y", "+2y", "-2y"} {
+		dir, output, code := runInDir(t, map[string]string{
+			"content/old.md": "---\ntitle: Old\ndate: 2020-01-01\n---\n",
+			"content/new.md": "---\ntitle: New\ndate: 2024-06-01\n---\n",
+		}, "expire", "--older-than", age, "--now", "2025-01-01", "--yes")
+		if code != 0 {
+			t.Fatalf("--older-than %s: exit %d: %s", age, code, output)
+		}
+		old, _ := os.ReadFile(filepath.Join(dir, "content/old.md"))
+		recent, _ := os.ReadFile(filepath.Join(dir, "content/new.md"))
+		if !strings.Contains(string(old), "expiryDate: 2025-01-01") || strings.Contains(string(recent), "expiryDate") {
+			t.Errorf("--older-than %s: expected only the old page to expire, got %q and %q", age, old, recent)
+		}
+	}
+}
+
+// TestExecute_NewNow tests that new dates content with --now.
+func TestExecute_NewNow(t *testing.T) {
+	dir, output, code := runInDir(t, nil, "new", "posts/hello.md", "--now", "2025-01-02T03:04:05Z")
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, output)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "content/posts/hello.md"))
+	if !strings.Contains(string(data), "date: 2025-01-02T03:04:05Z") {
+		t.Errorf("expected the --now date, got %q", data)
+	}
+}
+
+// TestExecute_KeepGoingOutput tests that the files --keep-going failed on
+// are not buried under the usage text and a repeated error.
+func TestExecute_KeepGoingOutput(t *testing.T) {
+	_, output, code := runInDir(t, map[string]string{
+		"content/a.md":      "---\ntitle: A\n---\n",
+		"content/broken.md": "---\ntitle: [oops\n---\n",
+	}, "--keep-going", "--set", "draft=true", "--yes")
+	if code != 1 {
+		t.Errorf("expected exit 1, got %d", code)
+	}
+	if !strings.Contains(output, "- content/broken.md:2: ") {
+		t.Errorf("expected the failed file with its line, got: %s", output)
+	}
+	if strings.Contains(output, "Usage:") {
+		t.Errorf("expected no usage text, got: %s", output)
+	}
+	if n := strings.Count(output, "1 file could not be processed"); n != 1 {
+		t.Errorf("expected the error once, got it %d times: %s", n, output)
+	}
+
+	// The last of --keep-going and --fail-fast wins
+	_, output, code = runInDir(t, map[string]string{
+		"content/a.md":      "---\ntitle: A\n---\n",
+		"content/broken.md": "---\ntitle: [oops\n---\n",
+	}, "--keep-going", "--fail-fast", "--set", "draft=true", "--yes")
+	if code != 1 || strings.Contains(output, "could not be processed") {
+		t.Errorf("expected --fail-fast to stop at the broken file, got exit %d: %s", code, output)
+	}
+
+	_, output, code = runInDir(t, nil, "--no-such-flag")
+	if code != 1 || !strings.Contains(output, "Usage:") || !strings.Contains(output, "unknown flag: --no-such-flag") {
+		t.Errorf("expected usage and the error for an unknown flag, got exit %d: %s", code, output)
+	}
+}
+
+// TestExecute_ArchiveKeepGoing tests archiving the other pages when one
+// cannot be parsed and another cannot be moved.
+func TestExecute_ArchiveKeepGoing(t *testing.T) {
+	dir, output, code := runInDir(t, map[string]string{
+		"content/posts/a.md":         "---\ntitle: A\nold: true\n---\n",
+		"content/posts/b.md":         "---\ntitle: B\nold: true\n---\n",
+		"content/archive/posts/b.md": "---\ntitle: B\n---\n",
+		"content/posts/broken.md":    "---\ntitle: [oops\n---\n",
+	}, "archive", "--if", "old=true", "--keep-going", "--yes")
+	if code != 1 {
+		t.Errorf("expected exit 1, got %d: %s", code, output)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "content/archive/posts/a.md")); err != nil {
+		t.Errorf("expected a.md to be archived: %v", err)
+	}
+	for _, want := range []string{"- content/posts/b.md: cannot archive to content/archive/posts/b.md", "- content/posts/broken.md:2: ", "2 files could not be processed"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in the output, got: %s", want, output)
+		}
+	}
+}
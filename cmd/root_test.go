@@ -3,6 +3,8 @@ package cmd
 
 import (
 	"testing"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/vfs"
 )
 
 // TestParseCSV tests the parseCSV function.
@@ -25,3 +27,25 @@ func TestParseCSV(t *testing.T) {
 		}
 	}
 }
+
+// closerFS is a vfs.FS recording whether it was closed.
+type closerFS struct {
+	vfs.FS
+	closed bool
+}
+
+func (c *closerFS) Close() error {
+	c.closed = true
+	return nil
+}
+
+// TestCloseSource tests releasing sources that hold resources, such as git trees.
+func TestCloseSource(t *testing.T) {
+	fsys := &closerFS{FS: vfs.NewMemFS(nil)}
+	closeSource(fsys)
+	if !fsys.closed {
+		t.Errorf("expected the source to be closed")
+	}
+	// Sources without resources are left alone
+	closeSource(vfs.OS{})
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal"
+	"github.com/spf13/cobra"
+)
+
+// newExportCmd returns the `export` command, which writes chosen fields to a spreadsheet.
+func newExportCmd() *cobra.Command {
+	var fields, format, output string
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write fields of the files matching --if to CSV or TSV for editing in a spreadsheet",
+		Long: "Write one row per file matching --if, keyed by a file column, with one column per field in --fields.\n" +
+			"Lists are joined with \", \" and missing fields are left empty. Edit the file and apply it with import.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			cfg, fsys, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			defer closeSource(fsys)
+			if format != "csv" && format != "tsv" {
+				return fmt.Errorf("invalid --format %q: use csv or tsv", format)
+			}
+			cfg.ExtractKeys = parseCSV(fields)
+			cfg.ExtractFormat = format
+
+			w := os.Stdout
+			if output != "" {
+				if w, err = os.Create(output); err != nil {
+					return err
+				}
+				defer func() {
+					if cerr := w.Close(); err == nil {
+						err = cerr
+					}
+				}()
+			}
+			_, err = internal.RunExport(cfg, fsys, w)
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&fields, "fields", "", "Comma-separated fields to export, e.g. title,date,tags,params.author")
+	cmd.Flags().StringVar(&format, "format", "csv", "Spreadsheet format: csv or tsv")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "File to write instead of standard output")
+	_ = cmd.MarkFlagRequired("fields")
+	return cmd
+}
+
+// newImportCmd returns the `import` command, which applies an edited spreadsheet.
+func newImportCmd() *cobra.Command {
+	var format string
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Apply the cells changed in a spreadsheet written by export",
+		Long: "Read a CSV or TSV file written by export and update the fields whose cells differ from the files,\n" +
+			"converting each cell to the type of the value it replaces. An emptied cell removes the field.\n" +
+			"Changes go through the usual diff and confirmation, and --gc. Rows naming missing files are reported.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, fsys, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			defer closeSource(fsys)
+			if format == "" {
+				format = "csv"
+				if strings.HasSuffix(strings.ToLower(args[0]), ".tsv") {
+					format = "tsv"
+				}
+			}
+			comma := ','
+			switch format {
+			case "csv":
+			case "tsv":
+				comma = '\t'
+			default:
+				return fmt.Errorf("invalid --format %q: use csv or tsv", format)
+			}
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			_, err = internal.RunImport(cfg, fsys, f, comma)
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "", "Spreadsheet format: csv or tsv (defaults to the file extension)")
+	return cmd
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal"
+	"github.com/spf13/cobra"
+)
+
+// newStatsCmd returns the `stats` command, which counts pages and values over the --if set.
+func newStatsCmd() *cobra.Command {
+	var opts internal.StatsOptions
+	var count bool
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Count pages matching --if, per value of a field or its distinct values",
+		Long: "Count the pages matching --if (--count), the pages per value of a field (--group-by), or list the\n" +
+			"distinct values of a field (--distinct). Lists such as tags count each item. Dates can be bucketed\n" +
+			"by year or month.",
+		Example: "  hugo-frontmatter-toolbox stats --group-by date --bucket year\n" +
+			"  hugo-frontmatter-toolbox stats --group-by tags --limit 50\n" +
+			"  hugo-frontmatter-toolbox stats --distinct type --format json",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, fsys, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			defer closeSource(fsys)
+			return internal.RunStats(cfg, fsys, opts, os.Stdout)
+		},
+	}
+	cmd.Flags().BoolVar(&count, "count", false, "Count the matching pages (the default without --group-by or --distinct)")
+	cmd.Flags().StringVar(&opts.GroupBy, "group-by", "", "Count pages per value of this field, e.g. author, tags or params.series")
+	cmd.Flags().StringVar(&opts.Distinct, "distinct", "", "List the distinct values of this field")
+	cmd.Flags().StringVar(&opts.Bucket, "bucket", "", "Group dates by year or month")
+	cmd.Flags().StringVar(&opts.Sort, "sort", "", "Order groups by count or value (default count, or value for --distinct and --bucket)")
+	cmd.Flags().IntVar(&opts.Limit, "limit", 0, "Show only the first N groups")
+	cmd.Flags().StringVar(&opts.Format, "format", "plain", "Output format: plain, csv or json")
+	cmd.MarkFlagsMutuallyExclusive("count", "group-by", "distinct")
+	return cmd
+}
@@ -0,0 +1,103 @@
+// Package aggregate counts frontmatter values across pages: group sizes,
+// distinct values and date histograms.
+package aggregate
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/helpers"
+)
+
+// Group is a value and the number of pages having it.
+type Group struct {
+	Value string
+	// Missing is true for the group of pages that lack the key.
+	Missing bool
+	Count   int
+}
+
+// Options controls how values are grouped and ordered.
+type Options struct {
+	// Bucket groups dates by "year" or "month"; empty groups by value.
+	Bucket string
+	// Sort orders groups by "count", largest first, or by "value". Ties
+	// are ordered by value.
+	Sort string
+	// Limit keeps the first Limit groups when positive.
+	Limit int
+	// Format turns a value into the text it is grouped by.
+	Format func(interface{}) string
+}
+
+// Counter accumulates the values pages have for one key.
+type Counter struct {
+	opts    Options
+	counts  map[string]int
+	missing int
+}
+
+// NewCounter returns an empty Counter.
+func NewCounter(opts Options) *Counter {
+	if opts.Format == nil {
+		opts.Format = func(v interface{}) string { return fmt.Sprintf("%v", v) }
+	}
+	return &Counter{opts: opts, counts: map[string]int{}}
+}
+
+// Add counts the value one page has for the key; ok is false when the page
+// lacks it. Each item of a list is counted, once per page.
+func (c *Counter) Add(v interface{}, ok bool) {
+	if !ok || v == nil {
+		c.missing++
+		return
+	}
+	items, isList := helpers.NormalizeValue(v).([]interface{})
+	if !isList {
+		items = []interface{}{v}
+	}
+	seen := map[string]bool{}
+	for _, item := range items {
+		key := c.key(item)
+		if !seen[key] {
+			seen[key] = true
+			c.counts[key]++
+		}
+	}
+}
+
+// key returns the group of v, bucketing dates when configured. Values that
+// are not dates keep their own group.
+func (c *Counter) key(v interface{}) string {
+	if c.opts.Bucket != "" {
+		if t, ok := helpers.ParseDate(v); ok {
+			if c.opts.Bucket == "month" {
+				return t.Format("2006-01")
+			}
+			return t.Format("2006")
+		}
+	}
+	return c.opts.Format(v)
+}
+
+// Groups returns the groups counted so far, ordered and limited as
+// configured. Pages lacking the key form the last group, if any.
+func (c *Counter) Groups() []Group {
+	groups := make([]Group, 0, len(c.counts)+1)
+	for value, n := range c.counts {
+		groups = append(groups, Group{Value: value, Count: n})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if c.opts.Sort == "count" && groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		return groups[i].Value < groups[j].Value
+	})
+	if c.opts.Limit > 0 && len(groups) > c.opts.Limit {
+		groups = groups[:c.opts.Limit]
+	}
+	if c.missing > 0 {
+		groups = append(groups, Group{Missing: true, Count: c.missing})
+	}
+	return groups
+}
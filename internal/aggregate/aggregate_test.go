@@ -0,0 +1,42 @@
+// Package aggregate_test contains unit tests for the aggregate package.
+package aggregate
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestCounter tests grouping, list items, missing values, sorting and limits.
+func TestCounter(t *testing.T) {
+	c := NewCounter(Options{Sort: "count", Limit: 2})
+	c.Add([]interface{}{"go", "web", "go"}, true)
+	c.Add([]interface{}{"go", "hugo"}, true)
+	c.Add("web", true)
+	c.Add(nil, false)
+	want := []Group{{Value: "go", Count: 2}, {Value: "web", Count: 2}, {Missing: true, Count: 1}}
+	if got := c.Groups(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Groups() = %v; want %v", got, want)
+	}
+}
+
+// TestCounter_Bucket tests grouping dates by year and month.
+func TestCounter_Bucket(t *testing.T) {
+	values := []interface{}{"2023-05-01", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), "2024-01-30T10:00:00Z", "soon"}
+	tests := []struct {
+		bucket string
+		want   []Group
+	}{
+		{"year", []Group{{Value: "2023", Count: 1}, {Value: "2024", Count: 2}, {Value: "soon", Count: 1}}},
+		{"month", []Group{{Value: "2023-05", Count: 1}, {Value: "2024-01", Count: 2}, {Value: "soon", Count: 1}}},
+	}
+	for _, tt := range tests {
+		c := NewCounter(Options{Bucket: tt.bucket, Sort: "value"})
+		for _, v := range values {
+			c.Add(v, true)
+		}
+		if got := c.Groups(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Groups() = %v; want %v", tt.bucket, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+// Package archetype creates content from Hugo archetypes and compares
+// existing pages to the archetype of their section.
+package archetype
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/helpers"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/site"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/vfs"
+)
+
+// defaultArchetype is used when the site has no archetype for a section, as in Hugo.
+const defaultArchetype = `---
+title: '{{ replace .File.ContentBaseName "-" " " | title }}'
+date: {{ .Date }}
+draft: true
+---
+`
+
+// Data is the template data available to an archetype, mirroring what
+// `hugo new` provides.
+type Data struct {
+	// Name is the content base name, e.g. "my-post" for posts/my-post.md or posts/my-post/index.md.
+	Name string
+	// Date is the creation time in RFC 3339 format.
+	Date string
+	// Type is the content type: the archetype kind, or else the section.
+	Type    string
+	Section string
+	File    File
+	// Site holds the site title, base URL and params.
+	Site map[string]interface{}
+}
+
+// File describes the content file being created.
+type File struct {
+	ContentBaseName string
+	BaseFileName    string
+	Dir             string
+	Path            string
+}
+
+// funcs are the template functions archetypes commonly use.
+var funcs = template.FuncMap{
+	"replace": func(s interface{}, old, new string) string {
+		return strings.ReplaceAll(fmt.Sprintf("%v", s), old, new)
+	},
+	"title": titleCase,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"trim":  strings.Trim,
+	"now":   time.Now,
+}
+
+// NewData returns the template data for the content file at rel, relative
+// to the content directory. kind overrides the content type when not empty.
+func NewData(s *site.Site, rel, kind string, now time.Time) Data {
+	rel = path.Clean(strings.ReplaceAll(rel, "\\", "/"))
+	dir, file := path.Split(rel)
+	base := strings.TrimSuffix(file, path.Ext(file))
+	name := base
+	if base == "index" || base == "_index" {
+		name = path.Base(dir)
+	}
+
+	d := Data{
+		Name:    name,
+		Date:    now.Format(time.RFC3339),
+		Section: SectionOf(rel),
+		File:    File{ContentBaseName: name, BaseFileName: base, Dir: dir, Path: rel},
+		Site:    map[string]interface{}{},
+	}
+	d.Type = d.Section
+	if kind != "" {
+		d.Type = kind
+	}
+	if s != nil {
+		d.Site["Title"] = s.Raw["title"]
+		d.Site["BaseURL"] = s.Raw["baseurl"]
+		d.Site["Params"] = s.Raw["params"]
+	}
+	return d
+}
+
+// SectionOf returns the section of the content file at rel, relative to the
+// content directory, or "" for top-level pages.
+func SectionOf(rel string) string {
+	rel = strings.TrimPrefix(path.Clean(strings.ReplaceAll(rel, "\\", "/")), "/")
+	if i := strings.Index(rel, "/"); i >= 0 {
+		return rel[:i]
+	}
+	return ""
+}
+
+// Find returns the path of the archetype for kind in dir of fsys:
+// <kind><ext>, falling back to default<ext>. It returns "" when neither exists.
+func Find(fsys fs.FS, dir, kind, ext string) string {
+	var names []string
+	if kind != "" {
+		names = append(names, kind+ext)
+	}
+	names = append(names, "default"+ext)
+	for _, name := range names {
+		p := path.Join(dir, name)
+		if info, err := fs.Stat(fsys, p); err == nil && !info.IsDir() {
+			return p
+		}
+	}
+	return ""
+}
+
+// Render executes the archetype template src with data.
+func Render(name string, src []byte, data Data) ([]byte, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Parse(string(src))
+	if err != nil {
+		return nil, fmt.Errorf("parsing archetype %s: %v", name, err)
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return nil, fmt.Errorf("rendering archetype %s: %v", name, err)
+	}
+	return out.Bytes(), nil
+}
+
+// Content renders the archetype for the content file at rel, which is
+// relative to the content directory. It checks that the result has valid
+// frontmatter.
+func Content(fsys fs.FS, s *site.Site, rel, kind string, now time.Time) ([]byte, error) {
+	data := NewData(s, rel, kind, now)
+	name, src := "default", []byte(defaultArchetype)
+	if p := Find(fsys, s.ArchetypeDir, data.Type, path.Ext(rel)); p != "" {
+		b, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return nil, err
+		}
+		name, src = p, b
+	}
+
+	out, err := Render(name, src, data)
+	if err != nil {
+		return nil, err
+	}
+	if delimiter, fmData, _ := helpers.SplitFrontmatter(out); delimiter != "" {
+		if _, err := helpers.UnmarshalFrontmatter(delimiter, fmData); err != nil {
+			return nil, fmt.Errorf("archetype %s produced invalid frontmatter: %v", name, err)
+		}
+	}
+	return out, nil
+}
+
+// Create writes the rendered archetype for rel to contentDir/rel in fsys and
+// returns the path written. It refuses to overwrite an existing file.
+func Create(fsys vfs.FS, s *site.Site, contentDir, rel, kind string, now time.Time) (string, error) {
+	target := path.Join(contentDir, rel)
+	if _, err := fs.Stat(fsys, target); err == nil {
+		return "", fmt.Errorf("%s already exists", target)
+	}
+	out, err := Content(fsys, s, rel, kind, now)
+	if err != nil {
+		return "", err
+	}
+	if err := fsys.WriteFile(target, out, 0600); err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+// Fields returns the frontmatter the archetype for kind defines, rendered
+// with placeholder data. It returns nil when the site has no such archetype.
+func Fields(fsys fs.FS, s *site.Site, kind, ext string) (map[string]interface{}, error) {
+	p := Find(fsys, s.ArchetypeDir, kind, ext)
+	if p == "" {
+		return nil, nil
+	}
+	src, err := fs.ReadFile(fsys, p)
+	if err != nil {
+		return nil, err
+	}
+	out, err := Render(p, src, NewData(s, path.Join(kind, "archetype"+ext), kind, time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	delimiter, fmData, _ := helpers.SplitFrontmatter(out)
+	if delimiter == "" {
+		return map[string]interface{}{}, nil
+	}
+	front, err := helpers.UnmarshalFrontmatter(delimiter, fmData)
+	if err != nil {
+		return nil, fmt.Errorf("archetype %s: %v", p, err)
+	}
+	return front, nil
+}
+
+// Missing returns the keys archetype defines that front lacks, sorted.
+func Missing(archetype, front map[string]interface{}) []string {
+	var keys []string
+	for k := range archetype {
+		if _, ok := front[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Title returns the title Hugo's default archetype gives the content
+// named name, e.g. "My Post" for "my-post".
+func Title(name string) string {
+	return titleCase(strings.ReplaceAll(name, "-", " "))
+}
+
+// titleCase upper-cases the first letter of every word in s.
+func titleCase(s string) string {
+	words := strings.Fields(s)
+	for i, w := range words {
+		r, size := utf8.DecodeRuneInString(w)
+		words[i] = string(unicode.ToUpper(r)) + w[size:]
+	}
+	return strings.Join(words, " ")
+}
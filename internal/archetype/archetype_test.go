@@ -0,0 +1,127 @@
+// Package archetype_test contains unit tests for the archetype package.
+package archetype
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/site"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/vfs"
+)
+
+// created is the fixed creation time used by the tests.
+var created = time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+
+// TestNewData tests the template data for plain pages and bundles.
+func TestNewData(t *testing.T) {
+	d := NewData(site.Default(), "posts/my-post/index.md", "", created)
+	if d.Name != "my-post" || d.Section != "posts" || d.Type != "posts" || d.Date != "2024-05-01T09:30:00Z" {
+		t.Errorf("unexpected data for a bundle: %+v", d)
+	}
+	d = NewData(site.Default(), "about.md", "page", created)
+	if d.Name != "about" || d.Section != "" || d.Type != "page" {
+		t.Errorf("unexpected data for a top-level page: %+v", d)
+	}
+}
+
+// TestContent tests rendering the section archetype, the default archetype
+// and Hugo's built-in fallback.
+func TestContent(t *testing.T) {
+	fsys := vfs.NewMemFS(map[string][]byte{
+		"archetypes/posts.md":   []byte("---\ntitle: \"{{ replace .Name \"-\" \" \" | title }}\"\ndate: {{ .Date }}\nauthor: \"\"\n---\n"),
+		"archetypes/default.md": []byte("+++\ntitle = \"{{ .Name | upper }}\"\n+++\n"),
+	})
+	s := site.Default()
+
+	tests := []struct {
+		rel, want string
+	}{
+		{"posts/hello-world.md", "---\ntitle: \"Hello World\"\ndate: 2024-05-01T09:30:00Z\nauthor: \"\"\n---\n"},
+		{"notes/todo.md", "+++\ntitle = \"TODO\"\n+++\n"},
+	}
+	for _, tt := range tests {
+		out, err := Content(fsys, s, tt.rel, "", created)
+		if err != nil {
+			t.Fatalf("Content(%q) error: %v", tt.rel, err)
+		}
+		if string(out) != tt.want {
+			t.Errorf("Content(%q) = %q; want %q", tt.rel, out, tt.want)
+		}
+	}
+
+	out, err := Content(vfs.NewMemFS(nil), s, "posts/first-post.md", "", created)
+	if err != nil {
+		t.Fatalf("Content() error: %v", err)
+	}
+	if !strings.Contains(string(out), "title: 'First Post'") || !strings.Contains(string(out), "draft: true") {
+		t.Errorf("unexpected built-in archetype output: %q", out)
+	}
+}
+
+// TestContent_Invalid tests that archetypes rendering broken frontmatter are rejected.
+func TestContent_Invalid(t *testing.T) {
+	fsys := vfs.NewMemFS(map[string][]byte{
+		"archetypes/default.md": []byte("---\ntitle: [{{ .Name }}\n---\n"),
+	})
+	if _, err := Content(fsys, site.Default(), "a.md", "", created); err == nil {
+		t.Error("expected an error for invalid frontmatter")
+	}
+}
+
+// TestCreate tests writing new content and refusing to overwrite it.
+func TestCreate(t *testing.T) {
+	fsys := vfs.NewMemFS(nil)
+	p, err := Create(fsys, site.Default(), "content", "posts/a.md", "", created)
+	if err != nil || p != "content/posts/a.md" {
+		t.Fatalf("Create() = %q, %v", p, err)
+	}
+	if _, err := fsys.ReadFile(p); err != nil {
+		t.Errorf("expected %s to be written: %v", p, err)
+	}
+	if _, err := Create(fsys, site.Default(), "content", "posts/a.md", "", created); err == nil {
+		t.Error("expected an error when the file exists")
+	}
+
+	p, err = Create(fsys, site.Default(), "content", "posts/élan-vital.md", "", created)
+	if err != nil {
+		t.Fatalf("Create() with a non-ASCII name: %v", err)
+	}
+	if data, _ := fsys.ReadFile(p); !strings.Contains(string(data), "title: 'Élan Vital'") {
+		t.Errorf("expected the title to be capitalised by rune, got %q", data)
+	}
+}
+
+// TestFieldsAndMissing tests comparing a page to its archetype.
+func TestFieldsAndMissing(t *testing.T) {
+	fsys := vfs.NewMemFS(map[string][]byte{
+		"archetypes/posts.md": []byte("---\ntitle: \"{{ .Name }}\"\ndate: {{ .Date }}\nsummary: \"\"\n---\n"),
+	})
+	fields, err := Fields(fsys, site.Default(), "posts", ".md")
+	if err != nil {
+		t.Fatalf("Fields() error: %v", err)
+	}
+	got := Missing(fields, map[string]interface{}{"title": "A"})
+	if want := []string{"date", "summary"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Missing() = %v; want %v", got, want)
+	}
+
+	fields, err = Fields(fsys, site.Default(), "docs", ".md")
+	if err != nil || fields != nil {
+		t.Errorf("expected no archetype for docs, got %v, %v", fields, err)
+	}
+}
+
+// TestTitle tests the default title given to content by name.
+func TestTitle(t *testing.T) {
+	for name, want := range map[string]string{
+		"my-first-post": "My First Post",
+		"élan-vital":    "Élan Vital",
+		"über-uns":      "Über Uns",
+	} {
+		if got := Title(name); got != want {
+			t.Errorf("Title(%q) = %q; want %q", name, got, want)
+		}
+	}
+}
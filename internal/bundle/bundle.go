@@ -0,0 +1,225 @@
+// Package bundle reads and checks the resources metadata of Hugo page
+// bundles: the files stored next to a bundle's index file.
+package bundle
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/helpers"
+)
+
+// Resource is one entry of a resources frontmatter list.
+type Resource struct {
+	// Src is the glob matching the files the entry applies to.
+	Src    string
+	Name   string
+	Title  string
+	Params map[string]interface{}
+}
+
+// imageExts are the file extensions treated as images.
+var imageExts = map[string]bool{
+	".avif": true, ".bmp": true, ".gif": true, ".jpeg": true, ".jpg": true,
+	".png": true, ".svg": true, ".tif": true, ".tiff": true, ".webp": true,
+}
+
+// IsImage reports whether name has an image file extension.
+func IsImage(name string) bool {
+	return imageExts[strings.ToLower(path.Ext(name))]
+}
+
+// IsLeafIndex reports whether the content file at p is the index file of a
+// leaf bundle, e.g. "posts/a/index.md" or "posts/a/index.fr.md".
+func IsLeafIndex(p string) bool {
+	return strings.SplitN(path.Base(p), ".", 2)[0] == "index"
+}
+
+// Files returns the resource files of the bundle in dir, relative to dir and
+// sorted. Files for which isContent is true, such as translations of the
+// index file, are left out.
+func Files(fsys fs.FS, dir string, isContent func(string) bool) ([]string, error) {
+	var files []string
+	err := fs.WalkDir(fsys, dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && !isContent(name) {
+			files = append(files, strings.TrimPrefix(name, dir+"/"))
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// Parse reads a resources frontmatter value. Entries that are not maps are
+// ignored.
+func Parse(raw interface{}) []Resource {
+	list, ok := helpers.NormalizeValue(raw).([]interface{})
+	if !ok {
+		return nil
+	}
+	var resources []Resource
+	for _, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		r := Resource{Src: toString(m["src"]), Name: toString(m["name"]), Title: toString(m["title"])}
+		r.Params, _ = m["params"].(map[string]interface{})
+		resources = append(resources, r)
+	}
+	return resources
+}
+
+// Match reports whether the bundle-relative file name matches the src glob
+// pattern as Hugo matches it: case-insensitively, with * and ? stopping at
+// "/", ** crossing directories, [...] classes and {a,b} alternatives.
+func Match(pattern, name string) bool {
+	var re strings.Builder
+	re.WriteString("(?i)^")
+	inClass, inAlt := false, false
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case inClass:
+			if c == ']' {
+				inClass = false
+			}
+			if c == '!' && pattern[i-1] == '[' {
+				c = '^'
+			}
+			re.WriteByte(c)
+		case c == '[':
+			inClass = true
+			re.WriteByte(c)
+		case c == '*' && i+1 < len(pattern) && pattern[i+1] == '*':
+			re.WriteString(".*")
+			i++
+		case c == '*':
+			re.WriteString("[^/]*")
+		case c == '?':
+			re.WriteString("[^/]")
+		case c == '{':
+			inAlt = true
+			re.WriteString("(?:")
+		case c == '}' && inAlt:
+			inAlt = false
+			re.WriteString(")")
+		case c == ',' && inAlt:
+			re.WriteString("|")
+		default:
+			re.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	re.WriteString("$")
+	matched, err := regexp.MatchString(re.String(), name)
+	return err == nil && matched
+}
+
+// Unmatched returns the src patterns of resources that match none of files.
+func Unmatched(resources []Resource, files []string) []string {
+	var out []string
+	for _, r := range resources {
+		if r.Src != "" && !matchesAny(r.Src, files) {
+			out = append(out, r.Src)
+		}
+	}
+	return out
+}
+
+// Unreferenced returns the images among files that no resources src matches.
+func Unreferenced(resources []Resource, files []string) []string {
+	var out []string
+	for _, name := range files {
+		if !IsImage(name) {
+			continue
+		}
+		referenced := false
+		for _, r := range resources {
+			if r.Src != "" && Match(r.Src, name) {
+				referenced = true
+				break
+			}
+		}
+		if !referenced {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// AddEntries appends a resources entry with src set to each of names to
+// front and reports how many it added.
+func AddEntries(front map[string]interface{}, names []string) int {
+	if len(names) == 0 {
+		return 0
+	}
+	var list []interface{}
+	switch v := front["resources"].(type) {
+	case []interface{}:
+		list = v
+	case []map[string]interface{}:
+		for _, m := range v {
+			list = append(list, m)
+		}
+	}
+	for _, name := range names {
+		list = append(list, map[string]interface{}{"src": name})
+	}
+	front["resources"] = list
+	return len(names)
+}
+
+// Ref is a file referenced from a frontmatter field.
+type Ref struct {
+	Key  string
+	Path string
+}
+
+// ImageRefs returns the file references in the featured_image and images
+// fields of front, leaving out empty values and absolute URLs.
+func ImageRefs(front map[string]interface{}) []Ref {
+	var refs []Ref
+	for _, key := range []string{"featured_image", "images"} {
+		var values []interface{}
+		switch v := front[key].(type) {
+		case []interface{}:
+			values = v
+		case nil:
+		default:
+			values = []interface{}{v}
+		}
+		for _, v := range values {
+			ref := strings.TrimSpace(toString(v))
+			if ref == "" || strings.Contains(ref, "://") || strings.HasPrefix(ref, "//") {
+				continue
+			}
+			refs = append(refs, Ref{Key: key, Path: ref})
+		}
+	}
+	return refs
+}
+
+// matchesAny reports whether pattern matches any of files.
+func matchesAny(pattern string, files []string) bool {
+	for _, name := range files {
+		if Match(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// toString formats a decoded scalar, or returns "" for nil.
+func toString(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -0,0 +1,89 @@
+// Package bundle_test contains unit tests for the bundle package.
+package bundle
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+// TestMatch tests Hugo's resource src glob syntax.
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		match         bool
+	}{
+		{"*.jpg", "cover.jpg", true},
+		{"*.jpg", "gallery/1.jpg", false},
+		{"gallery/*", "gallery/1.jpg", true},
+		{"**.jpg", "gallery/1.jpg", true},
+		{"*.JPG", "cover.jpg", true},
+		{"*.{jpg,png}", "logo.png", true},
+		{"img-[0-9].png", "img-3.png", true},
+		{"img-[!0-9].png", "img-3.png", false},
+		{"cover.?ng", "cover.png", true},
+		{"cover.jpg", "cover.jpeg", false},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.name); got != tt.match {
+			t.Errorf("Match(%q, %q) = %v; want %v", tt.pattern, tt.name, got, tt.match)
+		}
+	}
+}
+
+// TestUnmatchedAndUnreferenced tests comparing resources entries to bundle files.
+func TestUnmatchedAndUnreferenced(t *testing.T) {
+	resources := Parse([]interface{}{
+		map[interface{}]interface{}{"src": "gallery/*.jpg", "title": "Gallery", "params": map[interface{}]interface{}{"credits": "Me"}},
+		map[string]interface{}{"src": "*.pdf"},
+		"not an entry",
+	})
+	if len(resources) != 2 || resources[0].Title != "Gallery" || resources[0].Params["credits"] != "Me" {
+		t.Fatalf("unexpected resources: %+v", resources)
+	}
+	files := []string{"cover.jpg", "data.csv", "gallery/1.jpg"}
+	if got, want := Unmatched(resources, files), []string{"*.pdf"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmatched = %v; want %v", got, want)
+	}
+	if got, want := Unreferenced(resources, files), []string{"cover.jpg"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Unreferenced = %v; want %v", got, want)
+	}
+
+	front := map[string]interface{}{"resources": []interface{}{map[string]interface{}{"src": "*.pdf"}}}
+	if n := AddEntries(front, []string{"cover.jpg"}); n != 1 {
+		t.Errorf("AddEntries added %d entries; want 1", n)
+	}
+	want := []interface{}{map[string]interface{}{"src": "*.pdf"}, map[string]interface{}{"src": "cover.jpg"}}
+	if !reflect.DeepEqual(front["resources"], want) {
+		t.Errorf("resources = %v; want %v", front["resources"], want)
+	}
+}
+
+// TestFiles tests listing the resource files of a bundle.
+func TestFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"posts/a/index.md":      {},
+		"posts/a/index.fr.md":   {},
+		"posts/a/cover.jpg":     {},
+		"posts/a/gallery/1.jpg": {},
+	}
+	files, err := Files(fsys, "posts/a", func(name string) bool { return len(name) > 3 && name[len(name)-3:] == ".md" })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"cover.jpg", "gallery/1.jpg"}; !reflect.DeepEqual(files, want) {
+		t.Errorf("Files = %v; want %v", files, want)
+	}
+}
+
+// TestImageRefs tests reading featured_image and images references.
+func TestImageRefs(t *testing.T) {
+	front := map[string]interface{}{
+		"featured_image": "cover.jpg",
+		"images":         []interface{}{"/img/a.png", "https://example.com/b.png", ""},
+	}
+	want := []Ref{{Key: "featured_image", Path: "cover.jpg"}, {Key: "images", Path: "/img/a.png"}}
+	if got := ImageRefs(front); !reflect.DeepEqual(got, want) {
+		t.Errorf("ImageRefs = %v; want %v", got, want)
+	}
+}
@@ -0,0 +1,172 @@
+// Package cascade resolves the values Hugo cascades from section _index files
+// down to their descendants.
+package cascade
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/helpers"
+)
+
+// Page describes a page as cascade targets see it.
+type Page struct {
+	// Path is the Hugo path, e.g. "/posts/hello" or "/" for the home page.
+	Path string
+	// Kind is "home", "section" or "page".
+	Kind string
+	Lang string
+}
+
+// Rule is one entry of a cascade block.
+type Rule struct {
+	Values map[string]interface{}
+	// Target filters, empty when unset.
+	TargetPath string
+	TargetKind string
+	TargetLang string
+}
+
+// Parse reads the rules of a cascade frontmatter value, which is either a
+// single map or a list of maps, each with an optional _target (or target) filter.
+func Parse(raw interface{}) []Rule {
+	switch v := helpers.NormalizeValue(raw).(type) {
+	case map[string]interface{}:
+		return []Rule{parseRule(v)}
+	case []interface{}:
+		var rules []Rule
+		for _, item := range v {
+			if m, ok := item.(map[string]interface{}); ok {
+				rules = append(rules, parseRule(m))
+			}
+		}
+		return rules
+	}
+	return nil
+}
+
+// parseRule splits a cascade entry into its values and target filter.
+func parseRule(m map[string]interface{}) Rule {
+	rule := Rule{Values: map[string]interface{}{}}
+	for k, v := range m {
+		if k != "_target" && k != "target" {
+			rule.Values[k] = v
+			continue
+		}
+		if target, ok := v.(map[string]interface{}); ok {
+			rule.TargetPath = toString(target["path"])
+			rule.TargetKind = toString(target["kind"])
+			rule.TargetLang = toString(target["lang"])
+		}
+	}
+	return rule
+}
+
+// Applies reports whether the rule targets p.
+func (r Rule) Applies(p Page) bool {
+	if r.TargetKind != "" && !globMatch(r.TargetKind, p.Kind) {
+		return false
+	}
+	if r.TargetLang != "" && !globMatch(r.TargetLang, p.Lang) {
+		return false
+	}
+	if r.TargetPath != "" && !globMatch(r.TargetPath, p.Path) {
+		return false
+	}
+	return true
+}
+
+// Apply returns a copy of front with the values of the cascades inherited by
+// p added. levels holds the rules of each ancestor section, nearest first;
+// the page's own values win over any cascade, and nearer cascades win over
+// farther ones.
+func Apply(front map[string]interface{}, p Page, levels [][]Rule) map[string]interface{} {
+	out := make(map[string]interface{}, len(front))
+	for k, v := range front {
+		out[k] = v
+	}
+	for _, rules := range levels {
+		for _, rule := range rules {
+			if !rule.Applies(p) {
+				continue
+			}
+			for k, v := range rule.Values {
+				if _, ok := out[k]; !ok {
+					out[k] = v
+				}
+			}
+		}
+	}
+	return out
+}
+
+// PageOf describes the content file with the content-relative path rel,
+// without extension or language suffix, e.g. "posts/_index" or "posts/a/index".
+func PageOf(rel, lang string) Page {
+	dir, base := path.Split(rel)
+	dir = strings.TrimSuffix(dir, "/")
+	switch {
+	case base == "_index" && dir == "":
+		return Page{Path: "/", Kind: "home", Lang: lang}
+	case base == "_index":
+		return Page{Path: "/" + dir, Kind: "section", Lang: lang}
+	case base == "index":
+		return Page{Path: "/" + dir, Kind: "page", Lang: lang}
+	}
+	return Page{Path: "/" + rel, Kind: "page", Lang: lang}
+}
+
+// Ancestors returns the content-relative directories whose _index files can
+// cascade to the file at rel, nearest first. The root directory is "".
+func Ancestors(rel string) []string {
+	dir := path.Dir(rel)
+	if path.Base(rel) == "_index" {
+		// A section's own cascade applies to its descendants, not to itself
+		if dir == "." {
+			return nil
+		}
+		dir = path.Dir(dir)
+	}
+	var dirs []string
+	for {
+		if dir == "." {
+			return append(dirs, "")
+		}
+		dirs = append(dirs, dir)
+		dir = path.Dir(dir)
+	}
+}
+
+// globMatch matches s against a Hugo target glob, where ** crosses path separators.
+func globMatch(pattern, s string) bool {
+	var re strings.Builder
+	re.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				re.WriteString(".*")
+				i++
+			} else {
+				re.WriteString("[^/]*")
+			}
+		case '?':
+			re.WriteString("[^/]")
+		default:
+			re.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	re.WriteString("$")
+	matched, err := regexp.MatchString(re.String(), s)
+	return err == nil && matched
+}
+
+// toString formats a decoded scalar, or returns "" for nil.
+func toString(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", v)
+}
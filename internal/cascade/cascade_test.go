@@ -0,0 +1,112 @@
+// Package cascade_test contains unit tests for the cascade package.
+package cascade
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestParse tests reading single and list cascade blocks.
+func TestParse(t *testing.T) {
+	single := Parse(map[interface{}]interface{}{"draft": true})
+	if len(single) != 1 || single[0].Values["draft"] != true {
+		t.Errorf("unexpected rules for a single cascade: %+v", single)
+	}
+
+	list := Parse([]interface{}{
+		map[interface{}]interface{}{
+			"type":    "docs",
+			"_target": map[interface{}]interface{}{"path": "/docs/**", "kind": "page", "lang": "en"},
+		},
+		map[string]interface{}{"banner": "b.jpg"},
+	})
+	want := []Rule{
+		{Values: map[string]interface{}{"type": "docs"}, TargetPath: "/docs/**", TargetKind: "page", TargetLang: "en"},
+		{Values: map[string]interface{}{"banner": "b.jpg"}},
+	}
+	if !reflect.DeepEqual(list, want) {
+		t.Errorf("Parse() = %+v; want %+v", list, want)
+	}
+
+	if rules := Parse("nonsense"); rules != nil {
+		t.Errorf("expected no rules for a scalar, got %+v", rules)
+	}
+}
+
+// TestRule_Applies tests the _target filters.
+func TestRule_Applies(t *testing.T) {
+	page := Page{Path: "/docs/guide/install", Kind: "page", Lang: "en"}
+	tests := []struct {
+		rule Rule
+		want bool
+	}{
+		{Rule{}, true},
+		{Rule{TargetPath: "/docs/**"}, true},
+		{Rule{TargetPath: "/docs/*"}, false},
+		{Rule{TargetPath: "/docs/*/install"}, true},
+		{Rule{TargetKind: "section"}, false},
+		{Rule{TargetKind: "page", TargetLang: "en"}, true},
+		{Rule{TargetLang: "de"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.Applies(page); got != tt.want {
+			t.Errorf("%+v.Applies() = %v; want %v", tt.rule, got, tt.want)
+		}
+	}
+}
+
+// TestApply tests the precedence of page values and nested cascades.
+func TestApply(t *testing.T) {
+	front := map[string]interface{}{"title": "Install"}
+	levels := [][]Rule{
+		{{Values: map[string]interface{}{"layout": "guide"}}},
+		{
+			{Values: map[string]interface{}{"layout": "docs", "title": "ignored", "draft": true}},
+			{Values: map[string]interface{}{"banner": "x.jpg"}, TargetKind: "section"},
+		},
+	}
+	got := Apply(front, Page{Path: "/docs/guide/install", Kind: "page"}, levels)
+	want := map[string]interface{}{"title": "Install", "layout": "guide", "draft": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Apply() = %v; want %v", got, want)
+	}
+	if len(front) != 1 {
+		t.Errorf("expected Apply not to modify its input, got %v", front)
+	}
+}
+
+// TestPageOf tests mapping content paths to Hugo paths and kinds.
+func TestPageOf(t *testing.T) {
+	tests := []struct {
+		rel  string
+		want Page
+	}{
+		{"_index", Page{Path: "/", Kind: "home"}},
+		{"docs/_index", Page{Path: "/docs", Kind: "section"}},
+		{"docs/guide/index", Page{Path: "/docs/guide", Kind: "page"}},
+		{"docs/faq", Page{Path: "/docs/faq", Kind: "page"}},
+	}
+	for _, tt := range tests {
+		if got := PageOf(tt.rel, ""); got != tt.want {
+			t.Errorf("PageOf(%q) = %+v; want %+v", tt.rel, got, tt.want)
+		}
+	}
+}
+
+// TestAncestors tests listing the sections that cascade to a file.
+func TestAncestors(t *testing.T) {
+	tests := []struct {
+		rel  string
+		want []string
+	}{
+		{"faq", []string{""}},
+		{"docs/guide/index", []string{"docs/guide", "docs", ""}},
+		{"docs/_index", []string{""}},
+		{"_index", nil},
+	}
+	for _, tt := range tests {
+		if got := Ancestors(tt.rel); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Ancestors(%q) = %v; want %v", tt.rel, got, tt.want)
+		}
+	}
+}
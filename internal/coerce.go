@@ -0,0 +1,153 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/helpers"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// CoerceTypes are the types the coerce operation converts values to.
+var CoerceTypes = []string{"list", "bool", "int", "float", "date", "string"}
+
+// coerceFields converts the values of the keys in types to their target
+// type. Missing keys and null values are skipped. It returns the number of
+// values changed and a message for each value that cannot be converted,
+// which is left as it is.
+func coerceFields(types map[string]string, front map[string]interface{}) (int, []string) {
+	keys := make([]string, 0, len(types))
+	for key := range types {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	changed := 0
+	var issues []string
+	for _, key := range keys {
+		val, ok := helpers.Lookup(front, key)
+		if !ok || val == nil {
+			continue
+		}
+		converted, same, err := coerceValue(helpers.NormalizeValue(val), types[key])
+		if err != nil {
+			issues = append(issues, fmt.Sprintf("cannot convert '%s' to %s: %q", key, types[key], valueText(val)))
+			continue
+		}
+		if !same {
+			helpers.SetPath(front, key, converted)
+			changed++
+		}
+	}
+	return changed, issues
+}
+
+// coerceValue converts v to the type named typ. It reports whether v
+// already had that type, in which case it is returned as it is.
+func coerceValue(v interface{}, typ string) (interface{}, bool, error) {
+	invalid := fmt.Errorf("cannot convert %v to %s", v, typ)
+	switch typ {
+	case "list":
+		switch val := v.(type) {
+		case []interface{}:
+			return val, true, nil
+		case map[string]interface{}:
+			return nil, false, invalid
+		case string:
+			if strings.TrimSpace(val) == "" {
+				return []interface{}{}, false, nil
+			}
+		}
+		return []interface{}{v}, false, nil
+
+	case "bool":
+		switch val := v.(type) {
+		case bool:
+			return val, true, nil
+		case string:
+			switch strings.ToLower(strings.TrimSpace(val)) {
+			case "true", "yes", "on", "1":
+				return true, false, nil
+			case "false", "no", "off", "0":
+				return false, false, nil
+			}
+		case int, int64:
+			if n := fmt.Sprint(val); n == "0" || n == "1" {
+				return n == "1", false, nil
+			}
+		}
+		return nil, false, invalid
+
+	case "int":
+		switch val := v.(type) {
+		case int, int64, uint64:
+			return val, true, nil
+		case float64:
+			if val == math.Trunc(val) {
+				return int(val), false, nil
+			}
+		case string:
+			if n, err := strconv.Atoi(strings.TrimSpace(val)); err == nil {
+				return n, false, nil
+			}
+			if f, err := strconv.ParseFloat(strings.TrimSpace(val), 64); err == nil && f == math.Trunc(f) {
+				return int(f), false, nil
+			}
+		}
+		return nil, false, invalid
+
+	case "float":
+		switch val := v.(type) {
+		case float64:
+			return val, true, nil
+		case int:
+			return float64(val), false, nil
+		case int64:
+			return float64(val), false, nil
+		case string:
+			if f, err := strconv.ParseFloat(strings.TrimSpace(val), 64); err == nil {
+				return f, false, nil
+			}
+		}
+		return nil, false, invalid
+
+	case "date":
+		switch val := v.(type) {
+		case time.Time, toml.LocalDate, toml.LocalDateTime:
+			return val, true, nil
+		case string:
+			t, ok := helpers.ParseDate(val)
+			if !ok {
+				break
+			}
+			// Dates without a time keep to a plain date
+			if !strings.Contains(val, ":") {
+				return helpers.DateValue(t, "2006-01-02"), false, nil
+			}
+			return helpers.DateValue(t, time.RFC3339), false, nil
+		}
+		return nil, false, invalid
+
+	case "string":
+		switch val := v.(type) {
+		case string:
+			return val, true, nil
+		case []interface{}:
+			// A list of one scalar becomes that value
+			if len(val) == 1 {
+				if s, _, err := coerceValue(val[0], "string"); err == nil {
+					return s, false, nil
+				}
+			}
+		case map[string]interface{}:
+		default:
+			return valueText(val), false, nil
+		}
+		return nil, false, invalid
+	}
+	return nil, false, fmt.Errorf("unknown type %q: use %s", typ, strings.Join(CoerceTypes, ", "))
+}
@@ -0,0 +1,83 @@
+// Package internal_test contains unit tests for the internal package.
+package internal
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// TestCoerceValue tests converting values to each target type.
+func TestCoerceValue(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		typ   string
+		want  interface{}
+		same  bool
+		err   bool
+	}{
+		{"go", "list", []interface{}{"go"}, false, false},
+		{"", "list", []interface{}{}, false, false},
+		{[]interface{}{"go"}, "list", []interface{}{"go"}, true, false},
+		{map[string]interface{}{"a": 1}, "list", nil, false, true},
+		{"true", "bool", true, false, false},
+		{"No", "bool", false, false, false},
+		{1, "bool", true, false, false},
+		{true, "bool", true, true, false},
+		{"maybe", "bool", nil, false, true},
+		{"5", "int", 5, false, false},
+		{"2.0", "int", 2, false, false},
+		{float64(3), "int", 3, false, false},
+		{int64(7), "int", int64(7), true, false},
+		{"2.5", "int", nil, false, true},
+		{"1.5", "float", 1.5, false, false},
+		{4, "float", float64(4), false, false},
+		{"2024-01-01", "date", toml.LocalDate{Year: 2024, Month: 1, Day: 1}, false, false},
+		{"2024-01-01T10:00:00Z", "date", time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), false, false},
+		{"soon", "date", nil, false, true},
+		{5, "string", "5", false, false},
+		{[]interface{}{"go"}, "string", "go", false, false},
+		{[]interface{}{"go", "web"}, "string", nil, false, true},
+		{"x", "complex", nil, false, true},
+	}
+	for _, tt := range tests {
+		got, same, err := coerceValue(tt.value, tt.typ)
+		if (err != nil) != tt.err {
+			t.Errorf("coerceValue(%#v, %s) error = %v; want error %v", tt.value, tt.typ, err, tt.err)
+			continue
+		}
+		if err == nil && (!reflect.DeepEqual(got, tt.want) || same != tt.same) {
+			t.Errorf("coerceValue(%#v, %s) = %#v, %v; want %#v, %v", tt.value, tt.typ, got, same, tt.want, tt.same)
+		}
+	}
+}
+
+// TestCoerceFields tests converting fields, including nested paths, and
+// reporting values that cannot be converted.
+func TestCoerceFields(t *testing.T) {
+	front := map[string]interface{}{
+		"tags":   "go",
+		"draft":  "true",
+		"weight": "five",
+		"params": map[interface{}]interface{}{"rating": "4"},
+	}
+	types := map[string]string{"tags": "list", "draft": "bool", "weight": "int", "params.rating": "int", "series": "list"}
+	changed, issues := coerceFields(types, front)
+	if changed != 3 {
+		t.Errorf("changed = %d; want 3", changed)
+	}
+	want := map[string]interface{}{
+		"tags":   []interface{}{"go"},
+		"draft":  true,
+		"weight": "five",
+		"params": map[string]interface{}{"rating": 4},
+	}
+	if !reflect.DeepEqual(front, want) {
+		t.Errorf("front = %#v; want %#v", front, want)
+	}
+	if wantIssues := []string{`cannot convert 'weight' to int: "five"`}; !reflect.DeepEqual(issues, wantIssues) {
+		t.Errorf("issues = %v; want %v", issues, wantIssues)
+	}
+}
@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/helpers"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
+)
+
+// dateKeys returns the fields normalize-dates rewrites: cfg.DateKeys, or
+// else every key of the site's frontmatter date mappings, sorted.
+func dateKeys(cfg config.Config) []string {
+	if len(cfg.DateKeys) > 0 {
+		return cfg.DateKeys
+	}
+	seen := map[string]bool{}
+	var keys []string
+	for _, mapped := range siteOf(cfg).DateFields {
+		for _, key := range mapped {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// normalizeDates rewrites the date fields of front in cfg.DateLayout and
+// cfg.DateLocation, keeping native date types where the layout allows. It
+// reports whether any value changed and returns a message for each value
+// that could not be parsed, which is left as it is.
+func normalizeDates(cfg config.Config, front map[string]interface{}) (bool, []string) {
+	loc := cfg.DateLocation
+	if loc == nil {
+		loc = time.UTC
+	}
+	changed := false
+	var issues []string
+	for _, key := range dateKeys(cfg) {
+		val, ok := front[key]
+		if !ok {
+			continue
+		}
+		t, ok := helpers.ParseDateIn(val, loc)
+		if !ok {
+			issues = append(issues, fmt.Sprintf("unparseable date in '%s': %q", key, fmt.Sprintf("%v", val)))
+			continue
+		}
+		normalized := helpers.DateValue(t.In(loc), cfg.DateLayout)
+		if fmt.Sprintf("%T %v", normalized, normalized) != fmt.Sprintf("%T %v", val, val) {
+			front[key] = normalized
+			changed = true
+		}
+	}
+	return changed, issues
+}
@@ -0,0 +1,87 @@
+// Package internal_test contains unit tests for the internal package.
+package internal
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/vfs"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// TestNormalizeDates tests rewriting dates into a layout and time zone.
+func TestNormalizeDates(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	cfg := config.Config{DateLayout: time.RFC3339, DateLocation: london}
+	front := map[string]interface{}{
+		"date":        "2024-07-01",
+		"lastmod":     "Mar 1, 2024",
+		"publishDate": "2024-07-01T12:00:00Z",
+		"expiryDate":  "someday",
+		"title":       "2024-07-01",
+	}
+	changed, issues := normalizeDates(cfg, front)
+	if !changed {
+		t.Errorf("expected dates to change")
+	}
+	want := map[string]interface{}{
+		"date":        time.Date(2024, 7, 1, 0, 0, 0, 0, london),
+		"lastmod":     time.Date(2024, 3, 1, 0, 0, 0, 0, london),
+		"publishDate": time.Date(2024, 7, 1, 13, 0, 0, 0, london),
+		"expiryDate":  "someday",
+		"title":       "2024-07-01",
+	}
+	for key, w := range want {
+		got := front[key]
+		if wt, ok := w.(time.Time); ok {
+			if gt, ok := got.(time.Time); !ok || !gt.Equal(wt) || gt.Location() != london {
+				t.Errorf("%s = %#v; want %v", key, got, wt)
+			}
+		} else if got != w {
+			t.Errorf("%s = %#v; want %#v", key, got, w)
+		}
+	}
+	if wantIssues := []string{`unparseable date in 'expiryDate': "someday"`}; !reflect.DeepEqual(issues, wantIssues) {
+		t.Errorf("issues = %v; want %v", issues, wantIssues)
+	}
+
+	cfg = config.Config{DateLayout: "2006-01-02", DateKeys: []string{"date"}}
+	front = map[string]interface{}{"date": toml.LocalDate{Year: 2024, Month: 7, Day: 1}, "lastmod": "2024-07-01T10:00:00Z"}
+	if changed, _ := normalizeDates(cfg, front); changed {
+		t.Errorf("expected a date already in the layout to be left unchanged, got %v", front)
+	}
+	if front["lastmod"] != "2024-07-01T10:00:00Z" {
+		t.Errorf("expected fields outside DateKeys to be left alone, got %v", front["lastmod"])
+	}
+}
+
+// TestRunToolFS_NormalizeDates tests that normalised dates are written unquoted.
+func TestRunToolFS_NormalizeDates(t *testing.T) {
+	fsys := vfs.NewMemFS(map[string][]byte{
+		"content/a.md": []byte("---\ntitle: A\ndate: \"2024-03-01 10:00\"\n---\n"),
+		"content/b.md": []byte("+++\ntitle = \"B\"\ndate = \"Mar 1, 2024\"\n+++\n"),
+		"content/c.md": []byte("---\ntitle: C\ndate: 2024-03-01\n---\n"),
+	})
+	cfg := config.Config{ContentDir: "content", DateLayout: "2006-01-02", Yes: true}
+	res, err := captureRun(t, cfg, fsys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"content/a.md", "content/b.md"}; !reflect.DeepEqual(res.ModifiedFiles, want) {
+		t.Errorf("ModifiedFiles = %v; want %v", res.ModifiedFiles, want)
+	}
+	a, _ := fsys.ReadFile("content/a.md")
+	if !strings.Contains(string(a), "date: 2024-03-01\n") {
+		t.Errorf("expected an unquoted YAML date, got %q", a)
+	}
+	b, _ := fsys.ReadFile("content/b.md")
+	if !strings.Contains(string(b), "date = 2024-03-01\n") {
+		t.Errorf("expected a native TOML date, got %q", b)
+	}
+}
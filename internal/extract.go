@@ -0,0 +1,183 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/helpers"
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/report"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
+	"gopkg.in/yaml.v2"
+)
+
+// missingValue is shown by the plain and table formats for keys a file lacks.
+const missingValue = "<missing>"
+
+// extractRow reads the values of cfg.ExtractKeys from the frontmatter front
+// of the file at path. Keys may be dotted paths such as "params.author".
+func extractRow(cfg config.Config, path string, front map[string]interface{}) *report.Row {
+	row := &report.Row{File: path, Values: map[string]interface{}{}}
+	for _, key := range cfg.ExtractKeys {
+		if v, ok := helpers.Lookup(front, key); ok {
+			row.Values[key] = helpers.NormalizeValue(v)
+		}
+	}
+	return row
+}
+
+// writeExtract writes the extract rows of res to w in cfg.ExtractFormat:
+// plain (the default), table, csv, tsv, json, jsonl or yaml. Each row holds
+// the file path followed by one column per key. JSON and YAML keep lists and
+// maps as such; the other formats join list items with ", ". A key the file
+// lacks is shown as <missing> in plain and table, is left out of the JSON and
+// YAML objects, and is an empty cell in csv and tsv, as import expects. A
+// key set to null is null in JSON and YAML and empty text otherwise.
+func writeExtract(w io.Writer, cfg config.Config, res *report.Result) error {
+	keys := cfg.ExtractKeys
+	switch cfg.ExtractFormat {
+	case "", "plain":
+		for _, row := range res.Extracted {
+			parts := make([]string, len(keys))
+			for i, key := range keys {
+				parts[i] = fmt.Sprintf("%s = %s", key, cellText(row, key, missingValue))
+			}
+			fmt.Fprintf(w, "%s: %s\n", row.File, strings.Join(parts, "; "))
+		}
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		header := append([]string{"file"}, keys...)
+		rule := make([]string, len(header))
+		for i, h := range header {
+			rule[i] = strings.Repeat("-", len(h))
+		}
+		fmt.Fprintln(tw, strings.Join(header, "\t"))
+		fmt.Fprintln(tw, strings.Join(rule, "\t"))
+		for _, row := range res.Extracted {
+			cells := []string{row.File}
+			for _, key := range keys {
+				cells = append(cells, cellText(row, key, missingValue))
+			}
+			fmt.Fprintln(tw, strings.Join(cells, "\t"))
+		}
+		return tw.Flush()
+	case "csv", "tsv":
+		writer := csv.NewWriter(w)
+		if cfg.ExtractFormat == "tsv" {
+			writer.Comma = '\t'
+		}
+		_ = writer.Write(append([]string{"file"}, keys...))
+		for _, row := range res.Extracted {
+			cells := []string{row.File}
+			for _, key := range keys {
+				cells = append(cells, cellText(row, key, ""))
+			}
+			_ = writer.Write(cells)
+		}
+		writer.Flush()
+		return writer.Error()
+	case "json", "jsonl":
+		objects := make([]json.RawMessage, len(res.Extracted))
+		for i, row := range res.Extracted {
+			obj, err := rowJSON(row, keys)
+			if err != nil {
+				return err
+			}
+			objects[i] = obj
+		}
+		if cfg.ExtractFormat == "jsonl" {
+			for _, obj := range objects {
+				fmt.Fprintln(w, string(obj))
+			}
+			return nil
+		}
+		out, err := json.MarshalIndent(objects, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(out))
+	case "yaml":
+		docs := make([]yaml.MapSlice, len(res.Extracted))
+		for i, row := range res.Extracted {
+			doc := yaml.MapSlice{{Key: "file", Value: row.File}}
+			for _, key := range keys {
+				if v, ok := row.Values[key]; ok {
+					doc = append(doc, yaml.MapItem{Key: key, Value: v})
+				}
+			}
+			docs[i] = doc
+		}
+		out, err := yaml.Marshal(docs)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(out)
+		return err
+	default:
+		return fmt.Errorf("unsupported extract format %q: use plain, table, csv, tsv, json, jsonl or yaml", cfg.ExtractFormat)
+	}
+	return nil
+}
+
+// rowJSON encodes row as a JSON object with the file path followed by keys,
+// in order. Keys the file lacks are left out.
+func rowJSON(row report.Row, keys []string) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	buf.WriteString(`{"file":`)
+	file, _ := json.Marshal(row.File)
+	buf.Write(file)
+	for _, key := range keys {
+		value, ok := row.Values[key]
+		if !ok {
+			continue
+		}
+		k, _ := json.Marshal(key)
+		v, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %v", row.File, key, err)
+		}
+		buf.WriteString(",")
+		buf.Write(k)
+		buf.WriteString(":")
+		buf.Write(v)
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+// cellText formats the value of key in row as text: list items joined with
+// ", ", maps as JSON and dates in RFC 3339. missing is returned when the
+// file lacks the key.
+func cellText(row report.Row, key, missing string) string {
+	v, ok := row.Values[key]
+	if !ok {
+		return missing
+	}
+	return valueText(v)
+}
+
+// valueText formats a frontmatter value as text for the plain, table and
+// csv formats.
+func valueText(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		items := make([]string, len(val))
+		for i, item := range val {
+			items[i] = valueText(item)
+		}
+		return strings.Join(items, ", ")
+	case map[string]interface{}:
+		out, _ := json.Marshal(val)
+		return string(out)
+	case time.Time:
+		return val.Format(time.RFC3339)
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -0,0 +1,124 @@
+// Package internal_test contains unit tests for the internal package.
+package internal
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/site"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/vfs"
+)
+
+// TestWriteExtract tests every extract format with several keys.
+func TestWriteExtract(t *testing.T) {
+	fsys := vfs.NewMemFS(map[string][]byte{
+		"content/a.md": []byte("---\ntitle: A\ntags: [go, web]\nparams:\n  author: Ann\n---\n"),
+		"content/b.md": []byte("+++\ntitle = \"B, the second\"\ndraft = true\n+++\n"),
+		"content/c.md": []byte("---\ntitle: C\ndraft: false\n---\n"),
+	})
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"plain", "content/a.md: title = A; tags = go, web; params.author = Ann\n" +
+			"content/b.md: title = B, the second; tags = <missing>; params.author = <missing>\n"},
+		{"table", "file          title          tags       params.author\n" +
+			"----          -----          ----       -------------\n" +
+			"content/a.md  A              go, web    Ann\n" +
+			"content/b.md  B, the second  <missing>  <missing>\n"},
+		{"csv", "file,title,tags,params.author\ncontent/a.md,A,\"go, web\",Ann\ncontent/b.md,\"B, the second\",,\n"},
+		{"jsonl", `{"file":"content/a.md","title":"A","tags":["go","web"],"params.author":"Ann"}` + "\n" +
+			`{"file":"content/b.md","title":"B, the second"}` + "\n"},
+		{"json", "[\n  {\n    \"file\": \"content/a.md\",\n    \"title\": \"A\",\n    \"tags\": [\n      \"go\",\n      \"web\"\n    ],\n    \"params.author\": \"Ann\"\n  },\n" +
+			"  {\n    \"file\": \"content/b.md\",\n    \"title\": \"B, the second\"\n  }\n]\n"},
+		{"yaml", "- file: content/a.md\n  title: A\n  tags:\n  - go\n  - web\n  params.author: Ann\n" +
+			"- file: content/b.md\n  title: B, the second\n"},
+	}
+	for _, tt := range tests {
+		cfg := config.Config{
+			ContentDir:    "content",
+			ExtractKeys:   []string{"title", "tags", "params.author"},
+			ExtractFormat: tt.format,
+			Condition:     "title=A OR draft=true",
+		}
+		res, err := captureRun(t, cfg, fsys)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.format, err)
+		}
+		var out bytes.Buffer
+		if err := writeExtract(&out, cfg, res); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.format, err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.format, out.String(), tt.want)
+		}
+	}
+
+	if err := writeExtract(&bytes.Buffer{}, config.Config{ExtractFormat: "xml"}, nil); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
+
+// TestWriteExtract_Effective tests extracting the literal dates of a site's
+// pages, and the dates Hugo would use with --effective.
+func TestWriteExtract_Effective(t *testing.T) {
+	fsys := vfs.NewMemFS(map[string][]byte{
+		"content/a.md": []byte("---\ntitle: A\npublishDate: 2024-05-01\n---\n"),
+	})
+	for _, tt := range []struct {
+		effective bool
+		want      string
+	}{
+		{false, "content/a.md: date = <missing>; publishDate = 2024-05-01\n"},
+		{true, "content/a.md: date = 2024-05-01; publishDate = 2024-05-01\n"},
+	} {
+		cfg := config.Config{
+			ContentDir:  "content",
+			ExtractKeys: []string{"date", "publishDate"},
+			Effective:   tt.effective,
+			Site:        site.FromMap(map[string]interface{}{}),
+		}
+		res, err := captureRun(t, cfg, fsys)
+		if err != nil {
+			t.Fatalf("--effective=%v: unexpected error: %v", tt.effective, err)
+		}
+		var out bytes.Buffer
+		if err := writeExtract(&out, cfg, res); err != nil {
+			t.Fatalf("--effective=%v: unexpected error: %v", tt.effective, err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("--effective=%v: got %q; want %q", tt.effective, out.String(), tt.want)
+		}
+	}
+}
+
+// TestWriteExtract_NullAndMissing tests telling a key set to null apart from
+// a missing one.
+func TestWriteExtract_NullAndMissing(t *testing.T) {
+	fsys := vfs.NewMemFS(map[string][]byte{
+		"content/a.md": []byte("---\ntitle: A\nsummary: null\n---\n"),
+	})
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"plain", "content/a.md: summary = ; author = <missing>\n"},
+		{"jsonl", `{"file":"content/a.md","summary":null}` + "\n"},
+		{"yaml", "- file: content/a.md\n  summary: null\n"},
+	}
+	for _, tt := range tests {
+		cfg := config.Config{ContentDir: "content", ExtractKeys: []string{"summary", "author"}, ExtractFormat: tt.format}
+		res, err := captureRun(t, cfg, fsys)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.format, err)
+		}
+		var out bytes.Buffer
+		if err := writeExtract(&out, cfg, res); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.format, err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("%s: got %q; want %q", tt.format, out.String(), tt.want)
+		}
+	}
+}
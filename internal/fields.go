@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/schema"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/vfs"
+)
+
+// RunFields inventories the keys used by the files matching cfg.Condition,
+// including nested paths, and writes to w each key with its value types,
+// the number of files using it and sample values, followed by the keys
+// whose type varies between files. With asSchema set it writes the JSON
+// Schema inferred from the files instead.
+func RunFields(cfg config.Config, fsys vfs.FS, asSchema bool, w io.Writer) (*schema.Inventory, error) {
+	paths, views, err := matchingViews(cfg, fsys)
+	if err != nil {
+		return nil, err
+	}
+	inv := schema.New()
+	for i, path := range paths {
+		inv.Add(path, views[i])
+	}
+
+	if asSchema {
+		out, err := json.MarshalIndent(inv.Schema(), "", "  ")
+		if err != nil {
+			return inv, err
+		}
+		fmt.Fprintln(w, string(out))
+		return inv, nil
+	}
+
+	fields := inv.Fields()
+	fmt.Fprintf(w, "📋 %d fields in %d files:\n\n", len(fields), inv.Files)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "key\tfiles\ttypes\tsamples")
+	for _, f := range fields {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", f.Key, f.Files, typeSummary(f), strings.Join(f.Samples, ", "))
+	}
+	if err := tw.Flush(); err != nil {
+		return inv, err
+	}
+
+	var inconsistent []string
+	for _, f := range fields {
+		if !f.Inconsistent() {
+			continue
+		}
+		var parts []string
+		for _, t := range f.TypeNames() {
+			parts = append(parts, fmt.Sprintf("%s in %s (e.g. %s)", t, plural(f.Types[t], "file"), f.Examples[t]))
+		}
+		inconsistent = append(inconsistent, fmt.Sprintf("%s: %s", f.Key, strings.Join(parts, ", ")))
+	}
+	if len(inconsistent) > 0 {
+		fmt.Fprintf(w, "\n⚠️  Inconsistent types:\n")
+		for _, line := range inconsistent {
+			fmt.Fprintf(w, "- %s\n", line)
+		}
+	}
+	return inv, nil
+}
+
+// typeSummary lists the types of f, with per-type file counts when there
+// are several, e.g. "list(9), string(1)".
+func typeSummary(f *schema.Field) string {
+	names := f.TypeNames()
+	if len(names) == 1 {
+		return names[0]
+	}
+	parts := make([]string, len(names))
+	for i, t := range names {
+		parts[i] = fmt.Sprintf("%s(%d)", t, f.Types[t])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// plural formats n with noun, adding an s unless n is 1.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,41 @@
+// Package internal_test contains unit tests for the internal package.
+package internal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/vfs"
+)
+
+// TestRunFields tests the field inventory and the inconsistency report.
+func TestRunFields(t *testing.T) {
+	fsys := vfs.NewMemFS(map[string][]byte{
+		"content/a.md": []byte("---\ntitle: A\ntags: [go, web]\n---\n"),
+		"content/b.md": []byte("+++\ntitle = \"B\"\ntags = \"solo\"\n+++\n"),
+		"content/c.md": []byte("No frontmatter\n"),
+	})
+	var out bytes.Buffer
+	if _, err := RunFields(config.Config{ContentDir: "content"}, fsys, false, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "📋 2 fields in 2 files:\n\n" +
+		"key    files  types               samples\n" +
+		"tags   2      list(1), string(1)  [\"go\", \"web\"], \"solo\"\n" +
+		"title  2      string              \"A\", \"B\"\n" +
+		"\n⚠️  Inconsistent types:\n" +
+		"- tags: list in 1 file (e.g. content/a.md), string in 1 file (e.g. content/b.md)\n"
+	if out.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	if _, err := RunFields(config.Config{ContentDir: "content"}, fsys, true, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), `"required": [`) {
+		t.Errorf("expected a JSON Schema, got %s", out.String())
+	}
+}
@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"errors"
+	"io"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/report"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
+)
+
+// ErrNoMatch is returned by RunFilter when the document does not satisfy cfg.Condition.
+var ErrNoMatch = errors.New("document does not match condition")
+
+// stdinPath is the name used for the document read by RunFilter in extract rows and errors.
+const stdinPath = "<stdin>"
+
+// RunFilter reads a single document from r, applies the operations in cfg and
+// writes the full document to w. Documents that are left unchanged, including
+// those that do not match cfg.Condition, are copied to w byte for byte so the
+// filter is safe to use in pipelines. With cfg.ExtractKeys set the extract
+// row is written instead of the document. Either way ErrNoMatch is returned
+// when the document does not match cfg.Condition.
+func RunFilter(cfg config.Config, r io.Reader, w io.Writer) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	res := TransformFile(cfg, stdinPath, data)
+	if res.Err != nil {
+		return res.Err
+	}
+
+	if len(cfg.ExtractKeys) > 0 {
+		run := &report.Result{}
+		if res.Extract != nil {
+			run.AddExtract(*res.Extract)
+		}
+		if err := writeExtract(w, cfg, run); err != nil {
+			return err
+		}
+	} else {
+		out := data
+		if res.Changed() {
+			out = res.Content()
+		}
+		if _, err := w.Write(out); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Condition != "" && !res.Matched {
+		return ErrNoMatch
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+// Package internal_test contains unit tests for the internal package.
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
+)
+
+// TestRunFilter tests applying operations to a document read from a reader.
+func TestRunFilter(t *testing.T) {
+	in := "---\ntitle: Post\ndraft: true\n---\nBody\n"
+	var out bytes.Buffer
+
+	cfg := config.Config{SetField: "draft=false", Condition: "draft=true"}
+	if err := RunFilter(cfg, strings.NewReader(in), &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "---\ntitle: Post\ndraft: false\n---\nBody\n"
+	if out.String() != want {
+		t.Errorf("RunFilter output = %q; want %q", out.String(), want)
+	}
+}
+
+// TestRunFilter_NoMatch tests that unmatched documents pass through unchanged.
+func TestRunFilter_NoMatch(t *testing.T) {
+	in := "+++\ntitle = 'Post'\ndraft = false\n+++\nBody\n"
+	var out bytes.Buffer
+
+	cfg := config.Config{SetField: "draft=true", Condition: "draft=true"}
+	err := RunFilter(cfg, strings.NewReader(in), &out)
+	if !errors.Is(err, ErrNoMatch) {
+		t.Errorf("expected ErrNoMatch, got %v", err)
+	}
+	if out.String() != in {
+		t.Errorf("expected unchanged document, got %q", out.String())
+	}
+}
+
+// TestRunFilter_Extract tests extracting a value from stdin.
+func TestRunFilter_Extract(t *testing.T) {
+	var out bytes.Buffer
+	cfg := config.Config{ExtractKeys: []string{"title", "tags"}, ExtractFormat: "csv"}
+	if err := RunFilter(cfg, strings.NewReader("---\ntitle: Post\ntags: [a, b]\n---\n"), &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.String() != "file,title,tags\n<stdin>,Post,\"a, b\"\n" {
+		t.Errorf("unexpected extract output: %q", out.String())
+	}
+}
+
+// TestRunFilter_ExtractNoMatch tests that extract mode exits like the other
+// modes when the document does not match.
+func TestRunFilter_ExtractNoMatch(t *testing.T) {
+	var out bytes.Buffer
+	cfg := config.Config{ExtractKeys: []string{"title"}, ExtractFormat: "jsonl", Condition: "draft=true"}
+	err := RunFilter(cfg, strings.NewReader("---\ntitle: Post\ndraft: false\n---\n"), &out)
+	if !errors.Is(err, ErrNoMatch) {
+		t.Errorf("expected ErrNoMatch, got %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected no extract rows, got %q", out.String())
+	}
+}
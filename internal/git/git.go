@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/Daviey/hugo-frontmatter-toolbox/internal/report"
 	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
@@ -15,12 +16,12 @@ import (
 // It defaults to exec.Command but can be overridden for testing purposes.
 var execCommand = exec.Command // 👈 allows test override
 
-// CommitChanges commits the changes made to the files in the content directory.
-func CommitChanges(cfg config.Config) error {
+// CommitChanges commits the files modified during the run described by res.
+func CommitChanges(cfg config.Config, res *report.Result) error {
 	if _, err := os.Stat(".git"); os.IsNotExist(err) {
 		return fmt.Errorf("--gc enabled but no .git repo found")
 	}
-	args := append([]string{"add"}, report.ModifiedFiles...)
+	args := append([]string{"add"}, res.ModifiedFiles...)
 	if err := execCommand("git", args...).Run(); err != nil {
 		return fmt.Errorf("git add failed: %v", err)
 	}
@@ -34,6 +35,18 @@ func CommitChanges(cfg config.Config) error {
 	return nil
 }
 
+// AddedDate returns the author date of the commit that added the file at
+// path to the repository in the working directory. It reports false when
+// git is unavailable or the file is not committed.
+func AddedDate(path string) (time.Time, bool) {
+	out, err := execCommand("git", "log", "--diff-filter=A", "--follow", "--format=%aI", "-1", "--", path).Output()
+	if err != nil {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(string(out)))
+	return t, err == nil
+}
+
 // generateCommitMessage generates a commit message based on the configuration.
 func generateCommitMessage(cfg config.Config) string {
 	if cfg.GcMsg != "" {
@@ -43,6 +56,21 @@ func generateCommitMessage(cfg config.Config) string {
 	if cfg.SetField != "" {
 		parts = append(parts, fmt.Sprintf("set %s", cfg.SetField))
 	}
+	if cfg.Lifecycle != "" {
+		parts = append(parts, cfg.Lifecycle+" pages")
+	}
+	if cfg.Import != nil {
+		parts = append(parts, "imported spreadsheet edits")
+	}
+	if cfg.AddResources {
+		parts = append(parts, "added bundle resources")
+	}
+	if cfg.DateLayout != "" {
+		parts = append(parts, "normalized dates")
+	}
+	if len(cfg.Coerce) > 0 {
+		parts = append(parts, "coerced field types")
+	}
 	if cfg.Condition != "" {
 		parts = append(parts, fmt.Sprintf("filtered on %q", cfg.Condition))
 	}
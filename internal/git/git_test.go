@@ -5,7 +5,9 @@ import (
 	"os"
 	"os/exec"
 	"testing"
+	"time"
 
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/report"
 	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
 )
 
@@ -65,7 +67,8 @@ func TestCommitChanges_Success(t *testing.T) {
 		GcMsg: "test commit",
 	}
 
-	if err := CommitChanges(cfg); err != nil {
+	res := &report.Result{ModifiedFiles: []string{"content/post.md"}}
+	if err := CommitChanges(cfg, res); err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
 }
@@ -79,3 +82,24 @@ func TestHelperProcess(t *testing.T) {
 	}
 	os.Exit(0)
 }
+
+// TestAddedDate tests reading the date a file was added from git log.
+func TestAddedDate(t *testing.T) {
+	origExec := execCommand
+	defer func() { execCommand = origExec }()
+
+	execCommand = func(name string, arg ...string) *exec.Cmd {
+		return exec.Command("echo", "2024-03-01T10:00:00+01:00")
+	}
+	got, ok := AddedDate("content/post.md")
+	if want := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC); !ok || !got.Equal(want) {
+		t.Errorf("AddedDate() = %v, %v; want %v", got, ok, want)
+	}
+
+	execCommand = func(name string, arg ...string) *exec.Cmd {
+		return exec.Command("echo")
+	}
+	if _, ok := AddedDate("content/new.md"); ok {
+		t.Errorf("expected no date for a file git does not know")
+	}
+}
@@ -4,8 +4,11 @@ package helpers
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,16 +22,33 @@ func IsMarkdownFile(path string) bool {
 	return strings.HasSuffix(path, ".md")
 }
 
+// IsContentFile checks if a file path has one of the given extensions.
+// With no extensions it falls back to IsMarkdownFile.
+func IsContentFile(path string, exts []string) bool {
+	if len(exts) == 0 {
+		return IsMarkdownFile(path)
+	}
+	for _, ext := range exts {
+		if strings.HasSuffix(path, ext) {
+			return true
+		}
+	}
+	return false
+}
+
 // SplitFrontmatter attempts to split a byte slice into frontmatter delimiter, frontmatter content, and body content.
+// A frontmatter block that is never closed is not split; see UnclosedFrontmatter.
 func SplitFrontmatter(data []byte) (string, []byte, []byte) {
 	content := string(data)
 	if strings.HasPrefix(content, "---\n") {
-		parts := strings.SplitN(content[4:], "---", 2)
-		return "---", []byte(parts[0]), []byte(parts[1])
+		if parts := strings.SplitN(content[4:], "---", 2); len(parts) == 2 {
+			return "---", []byte(parts[0]), []byte(parts[1])
+		}
 	}
 	if strings.HasPrefix(content, "+++\n") {
-		parts := strings.SplitN(content[4:], "+++", 2)
-		return "+++", []byte(parts[0]), []byte(parts[1])
+		if parts := strings.SplitN(content[4:], "+++", 2); len(parts) == 2 {
+			return "+++", []byte(parts[0]), []byte(parts[1])
+		}
 	}
 	if strings.HasPrefix(content, "{") {
 		idx := strings.Index(content, "}\n")
@@ -39,6 +59,79 @@ func SplitFrontmatter(data []byte) (string, []byte, []byte) {
 	return "", nil, data
 }
 
+// UnclosedFrontmatter returns the delimiter of a YAML or TOML frontmatter
+// block that data opens but never closes, or "" if there is none.
+func UnclosedFrontmatter(data []byte) string {
+	if delimiter, _, _ := SplitFrontmatter(data); delimiter != "" {
+		return ""
+	}
+	for _, delimiter := range []string{YamlDelimiter, TomlDelimiter} {
+		if bytes.HasPrefix(data, []byte(delimiter+"\n")) {
+			return delimiter
+		}
+	}
+	return ""
+}
+
+// yamlErrorLine matches the line number in YAML decoding errors.
+var yamlErrorLine = regexp.MustCompile(`line (\d+)`)
+
+// ErrorLine returns the line of the document that err, an error from
+// UnmarshalFrontmatter for the frontmatter fm with delimiter, refers to,
+// counting the opening delimiter line. It returns 0 when the error has no
+// position.
+func ErrorLine(delimiter string, fm []byte, err error) int {
+	switch delimiter {
+	case YamlDelimiter:
+		if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			return n + 1
+		}
+	case TomlDelimiter:
+		var decodeErr *toml.DecodeError
+		if errors.As(err, &decodeErr) {
+			row, _ := decodeErr.Position()
+			return row + 1
+		}
+	case JsonDelimiter:
+		var offset int64 = -1
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &syntaxErr) {
+			offset = syntaxErr.Offset
+		} else if errors.As(err, &typeErr) {
+			offset = typeErr.Offset
+		}
+		// The offset is just past the byte the decoder stopped at
+		if offset > 0 && offset <= int64(len(fm)) {
+			return bytes.Count(fm[:offset-1], []byte("\n")) + 1
+		}
+	}
+	return 0
+}
+
+// JoinFrontmatter reassembles a document from its delimiter, frontmatter content and body.
+// It is the inverse of SplitFrontmatter.
+func JoinFrontmatter(delimiter string, fm, body []byte) []byte {
+	var buf bytes.Buffer
+	if delimiter == JsonDelimiter {
+		buf.Write(fm)
+		if len(fm) > 0 && fm[len(fm)-1] != '\n' {
+			buf.WriteString("\n")
+		}
+	} else {
+		buf.WriteString(delimiter + "\n")
+		buf.Write(fm)
+		buf.WriteString(delimiter)
+		// SplitFrontmatter leaves the newline after the closing delimiter on the body
+		if !bytes.HasPrefix(body, []byte("\n")) && !bytes.HasPrefix(body, []byte("\r\n")) {
+			buf.WriteString("\n")
+		}
+	}
+	buf.Write(body)
+	return buf.Bytes()
+}
+
 // UnmarshalFrontmatter unmarshals frontmatter data based on the specified delimiter (---, +++, or {).
 func UnmarshalFrontmatter(delimiter string, data []byte) (map[string]interface{}, error) {
 	front := make(map[string]interface{})
@@ -56,8 +149,17 @@ func UnmarshalFrontmatter(delimiter string, data []byte) (map[string]interface{}
 	return front, fmt.Errorf("unknown frontmatter format: %s", delimiter)
 }
 
+// DefaultFieldOrder is the order in which well-known fields are written to YAML frontmatter.
+var DefaultFieldOrder = []string{"title", "date", "draft", "series", "categories", "tags"}
+
 // MarshalFrontmatter marshals frontmatter data based on the specified delimiter (---, +++, or {).
 func MarshalFrontmatter(delimiter string, front map[string]interface{}) ([]byte, error) {
+	return MarshalFrontmatterOrdered(delimiter, front, DefaultFieldOrder)
+}
+
+// MarshalFrontmatterOrdered is like MarshalFrontmatter but writes the YAML
+// fields in orderedFields first, followed by the rest in alphabetical order.
+func MarshalFrontmatterOrdered(delimiter string, front map[string]interface{}, orderedFields []string) ([]byte, error) {
 	// Make a copy of the map to avoid modifying the original
 	frontCopy := make(map[string]interface{})
 	for k, v := range front {
@@ -69,9 +171,6 @@ func MarshalFrontmatter(delimiter string, front map[string]interface{}) ([]byte,
 		// For YAML, we'll create a custom ordered output to ensure consistent field order
 		var orderedYAML strings.Builder
 
-		// Define the order of frontmatter fields
-		orderedFields := []string{"title", "date", "draft", "series", "categories", "tags"}
-
 		// First, add the ordered fields if they exist
 		for _, field := range orderedFields {
 			if value, exists := frontCopy[field]; exists {
@@ -104,8 +203,16 @@ func MarshalFrontmatter(delimiter string, front map[string]interface{}) ([]byte,
 		}
 		sort.Strings(keys)
 
+		// Tables go last: every key after a table header would belong to it
+		sort.SliceStable(keys, func(i, j int) bool {
+			return !hasMap(frontCopy[keys[i]]) && hasMap(frontCopy[keys[j]])
+		})
+
 		for _, key := range keys {
 			value := frontCopy[key]
+			if hasMap(value) {
+				value = NormalizeValue(value)
+			}
 
 			switch v := value.(type) {
 			case []string:
@@ -117,6 +224,15 @@ func MarshalFrontmatter(delimiter string, front map[string]interface{}) ([]byte,
 				fmt.Fprintf(&buf, "%s = [%s]\n", key, strings.Join(parts, ", "))
 
 			case []interface{}:
+				if hasMap(v) {
+					// Arrays of tables are written by the TOML encoder
+					fieldData, err := toml.Marshal(map[string]interface{}{key: v})
+					if err != nil {
+						return nil, err
+					}
+					buf.Write(fieldData)
+					continue
+				}
 				// Format interface arrays inline
 				var parts []string
 				for _, item := range v {
@@ -139,7 +255,10 @@ func MarshalFrontmatter(delimiter string, front map[string]interface{}) ([]byte,
 				fmt.Fprintf(&buf, "%s = %v\n", key, v)
 
 			case time.Time:
-				fmt.Fprintf(&buf, "%s = %q\n", key, v.Format(time.RFC3339))
+				fmt.Fprintf(&buf, "%s = %s\n", key, v.Format(time.RFC3339))
+
+			case toml.LocalDate, toml.LocalDateTime:
+				fmt.Fprintf(&buf, "%s = %v\n", key, v)
 
 			default:
 				// For complex types, use standard TOML marshaling
@@ -174,6 +293,14 @@ func MarshalFrontmatter(delimiter string, front map[string]interface{}) ([]byte,
 
 // addYAMLField adds a field to the YAML builder with proper formatting
 func addYAMLField(builder *strings.Builder, field string, value interface{}) {
+	// Maps, and lists holding them, are written in block style
+	if hasMap(value) {
+		if out, err := yaml.Marshal(map[string]interface{}{field: NormalizeValue(value)}); err == nil {
+			builder.Write(out)
+			return
+		}
+	}
+
 	// Handle arrays specially for inline format
 	switch v := value.(type) {
 	case []interface{}, []string:
@@ -185,6 +312,21 @@ func addYAMLField(builder *strings.Builder, field string, value interface{}) {
 	}
 }
 
+// hasMap reports whether v is a map or a list holding one.
+func hasMap(v interface{}) bool {
+	switch val := v.(type) {
+	case map[string]interface{}, map[interface{}]interface{}, []map[string]interface{}:
+		return true
+	case []interface{}:
+		for _, item := range val {
+			if hasMap(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // formatArrayItems converts array items to properly formatted strings
 func formatArrayItems(arr interface{}) []string {
 	var items []string
@@ -215,6 +357,10 @@ func formatYAMLValue(value interface{}) string {
 		return v
 	case bool, int, float64:
 		return fmt.Sprintf("%v", v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case toml.LocalDate, toml.LocalDateTime:
+		return fmt.Sprintf("%v", v)
 	default:
 		return fmt.Sprintf("%v", v)
 	}
@@ -227,6 +373,11 @@ func needsQuotes(s string) bool {
 		return true
 	}
 
+	// Dates stay unquoted so they remain YAML timestamps
+	if isTimestamp(s) {
+		return false
+	}
+
 	// Check for common values that need quotes
 	if s == "true" || s == "false" || s == "yes" || s == "no" || s == "null" || s == "~" {
 		return true
@@ -252,6 +403,226 @@ func needsQuotes(s string) bool {
 	return false
 }
 
+// timestampLayouts are the string forms written unquoted as YAML timestamps.
+var timestampLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"}
+
+// isTimestamp reports whether s is a date or date-time YAML reads as a timestamp.
+func isTimestamp(s string) bool {
+	for _, layout := range timestampLayouts {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveDateFields returns a copy of front in which each date field, such as
+// "date", holds the value of the first present key in its Hugo frontmatter
+// mapping, e.g. ["date", "publishDate"]. front itself is not modified.
+func ResolveDateFields(front map[string]interface{}, mapping map[string][]string) map[string]interface{} {
+	resolved := make(map[string]interface{}, len(front))
+	for k, v := range front {
+		resolved[k] = v
+	}
+	for field, keys := range mapping {
+		for _, key := range keys {
+			if v, ok := front[key]; ok {
+				resolved[field] = v
+				break
+			}
+		}
+	}
+	return resolved
+}
+
+// DateLayouts are the string date formats Hugo accepts in frontmatter, and
+// the minute-precision forms common in hand-written files, in the order
+// ParseDate tries them. Layouts without a zone are read as UTC.
+var DateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04:05-0700",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05 -07:00",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC822Z,
+	time.RFC822,
+	time.RFC850,
+	time.ANSIC,
+	time.UnixDate,
+	time.RubyDate,
+	"02 Jan 2006",
+	"Jan 2, 2006",
+	"January 2, 2006",
+}
+
+// ParseDate converts a decoded frontmatter value to a time: a time.Time,
+// a TOML local date or date-time, or a string in one of DateLayouts.
+// Values without a time zone are read as UTC.
+func ParseDate(v interface{}) (time.Time, bool) {
+	return ParseDateIn(v, time.UTC)
+}
+
+// ParseDateIn is like ParseDate but reads values without a time zone, such
+// as date-only strings and TOML local dates, as wall-clock time in loc.
+func ParseDateIn(v interface{}, loc *time.Location) (time.Time, bool) {
+	switch d := v.(type) {
+	case time.Time:
+		return d, true
+	case toml.LocalDate:
+		return d.AsTime(loc), true
+	case toml.LocalDateTime:
+		return d.AsTime(loc), true
+	case string:
+		s := strings.TrimSpace(d)
+		for _, layout := range DateLayouts {
+			if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// DateValue formats t with layout as a frontmatter value that keeps its
+// type where the format has one: a time.Time for RFC 3339, which is written
+// as a native YAML or TOML timestamp, a TOML local date or date-time for
+// "2006-01-02" and "2006-01-02T15:04:05", and a string for any other layout.
+func DateValue(t time.Time, layout string) interface{} {
+	switch layout {
+	case time.RFC3339:
+		return t.Truncate(time.Second)
+	case "2006-01-02":
+		return toml.LocalDate{Year: t.Year(), Month: int(t.Month()), Day: t.Day()}
+	case "2006-01-02T15:04:05":
+		return toml.LocalDateTime{
+			LocalDate: toml.LocalDate{Year: t.Year(), Month: int(t.Month()), Day: t.Day()},
+			LocalTime: toml.LocalTime{Hour: t.Hour(), Minute: t.Minute(), Second: t.Second()},
+		}
+	}
+	return t.Format(layout)
+}
+
+// ShiftDate returns t moved by offset, a signed count and unit such as
+// "-90d", "2y" or "+6m". Units are y (years), m (months), w (weeks), d (days)
+// and h (hours).
+func ShiftDate(t time.Time, offset string) (time.Time, error) {
+	offset = strings.TrimSpace(offset)
+	if len(offset) < 2 {
+		return t, fmt.Errorf("invalid offset %q", offset)
+	}
+	n, err := strconv.Atoi(offset[:len(offset)-1])
+	if err != nil {
+		return t, fmt.Errorf("invalid offset %q", offset)
+	}
+	switch offset[len(offset)-1] {
+	case 'y':
+		return t.AddDate(n, 0, 0), nil
+	case 'm':
+		return t.AddDate(0, n, 0), nil
+	case 'w':
+		return t.AddDate(0, 0, 7*n), nil
+	case 'd':
+		return t.AddDate(0, 0, n), nil
+	case 'h':
+		return t.Add(time.Duration(n) * time.Hour), nil
+	}
+	return t, fmt.Errorf("invalid offset %q: unit must be y, m, w, d or h", offset)
+}
+
+// NormalizeValue converts the map[interface{}]interface{} values produced by
+// the YAML decoder into map[string]interface{}, recursively, so nested
+// frontmatter can be handled the same way whatever its format.
+func NormalizeValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			out[fmt.Sprintf("%v", k)] = NormalizeValue(item)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			out[k] = NormalizeValue(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = NormalizeValue(item)
+		}
+		return out
+	}
+	return v
+}
+
+// Lookup returns the value of key in front. A key that is not set is read
+// as a dotted path through nested maps, e.g. "params.author".
+func Lookup(front map[string]interface{}, key string) (interface{}, bool) {
+	if v, ok := front[key]; ok {
+		return v, true
+	}
+	var cur interface{} = front
+	for _, part := range strings.Split(key, ".") {
+		m, ok := NormalizeValue(cur).(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// SetPath sets key in front to value. A key that is not set is treated as a
+// dotted path, e.g. "params.author", creating the maps along it as needed.
+func SetPath(front map[string]interface{}, key string, value interface{}) {
+	if _, ok := front[key]; ok || !strings.Contains(key, ".") {
+		front[key] = value
+		return
+	}
+	parts := strings.Split(key, ".")
+	parent := front
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := NormalizeValue(parent[part]).(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{}
+		}
+		parent[part] = child
+		parent = child
+	}
+	parent[parts[len(parts)-1]] = value
+}
+
+// DeletePath removes key from front, reading a key that is not set as a
+// dotted path like SetPath does.
+func DeletePath(front map[string]interface{}, key string) {
+	if _, ok := front[key]; ok || !strings.Contains(key, ".") {
+		delete(front, key)
+		return
+	}
+	parts := strings.Split(key, ".")
+	parent := front
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := NormalizeValue(parent[part]).(map[string]interface{})
+		if !ok {
+			return
+		}
+		parent[part] = child
+		parent = child
+	}
+	delete(parent, parts[len(parts)-1])
+}
+
 // ParseSet parses a string in the format "key=value" and returns the key and value as a string and interface{}.
 func ParseSet(input string) (string, interface{}) {
 	parts := strings.SplitN(input, "=", 2)
@@ -267,7 +638,14 @@ func ParseSet(input string) (string, interface{}) {
 }
 
 // EvaluateConditions evaluates a complex condition string against the frontmatter data.
+// Relative dates such as "now-1y" are taken relative to the current time.
 func EvaluateConditions(front map[string]interface{}, cond string) bool {
+	return EvaluateConditionsAt(front, cond, time.Now())
+}
+
+// EvaluateConditionsAt is like EvaluateConditions but evaluates relative
+// dates such as "now", "today" and "-90d" against now.
+func EvaluateConditionsAt(front map[string]interface{}, cond string, now time.Time) bool {
 	// Replace AND/OR with their symbolic equivalents
 	cond = strings.ReplaceAll(cond, " AND ", " && ")
 	cond = strings.ReplaceAll(cond, " OR ", " || ")
@@ -282,7 +660,7 @@ func EvaluateConditions(front map[string]interface{}, cond string) bool {
 		for _, andClause := range ands {
 			andClause = strings.TrimSpace(andClause)
 			// Check if the individual condition is met
-			if !CheckCondition(front, andClause) {
+			if !CheckConditionAt(front, andClause, now) {
 				all = false
 				break
 			}
@@ -298,18 +676,26 @@ func EvaluateConditions(front map[string]interface{}, cond string) bool {
 
 // CheckCondition checks if a single condition is met within the frontmatter data.
 func CheckCondition(front map[string]interface{}, cond string) bool {
-	// Date comparison
-	if strings.Contains(cond, "<") && strings.HasPrefix(cond, "date") {
-		cutoff := strings.TrimSpace(strings.Split(cond, "<")[1])
-		cutoffTime, _ := time.Parse("2006-01-02", cutoff)
-		if val, ok := front["date"]; ok {
-			dateStr := fmt.Sprintf("%v", val)
-			postTime, err := time.Parse("2006-01-02", dateStr)
-			if err == nil && postTime.Before(cutoffTime) {
-				return true
-			}
+	return CheckConditionAt(front, cond, time.Now())
+}
+
+// comparisonOps are the ordering operators of conditions, longest first.
+var comparisonOps = []string{"<=", ">=", "<", ">"}
+
+// CheckConditionAt is like CheckCondition but evaluates relative dates against now.
+func CheckConditionAt(front map[string]interface{}, cond string, now time.Time) bool {
+	// Recency check: lastmod within 30d
+	if key, offset, ok := strings.Cut(cond, " within "); ok {
+		val, ok := front[strings.TrimSpace(key)]
+		if !ok {
+			return false
 		}
-		return false
+		t, ok := ParseDate(val)
+		if !ok {
+			return false
+		}
+		since, err := ShiftDate(now, "-"+strings.TrimLeft(strings.TrimSpace(offset), "+-"))
+		return err == nil && !t.Before(since) && !t.After(now)
 	}
 
 	// Contains check for arrays
@@ -330,6 +716,32 @@ func CheckCondition(front map[string]interface{}, cond string) bool {
 		return false
 	}
 
+	// Ordering comparison of dates or numbers: date < now-1y, weight >= 10
+	for _, op := range comparisonOps {
+		key, operand, ok := strings.Cut(cond, op)
+		if !ok {
+			continue
+		}
+		val, ok := front[strings.TrimSpace(key)]
+		if !ok {
+			return false
+		}
+		cmp, ok := compareValues(val, strings.Trim(strings.TrimSpace(operand), "\"'"), now)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "<=":
+			return cmp <= 0
+		case ">=":
+			return cmp >= 0
+		case "<":
+			return cmp < 0
+		default:
+			return cmp > 0
+		}
+	}
+
 	// Equality check
 	if strings.Contains(cond, "=") {
 		parts := strings.Split(cond, "=")
@@ -343,6 +755,65 @@ func CheckCondition(front map[string]interface{}, cond string) bool {
 	return false
 }
 
+// compareValues compares a frontmatter value to the operand of a condition,
+// as dates if both are dates and as numbers if both are numbers. It returns
+// -1, 0 or 1, and false when the two cannot be compared.
+func compareValues(val interface{}, operand string, now time.Time) (int, bool) {
+	if want, ok := ParseDateExpr(operand, now); ok {
+		got, ok := ParseDate(val)
+		if !ok {
+			return 0, false
+		}
+		return got.Compare(want), true
+	}
+	want, err := strconv.ParseFloat(operand, 64)
+	if err != nil {
+		return 0, false
+	}
+	got, err := strconv.ParseFloat(fmt.Sprintf("%v", val), 64)
+	if err != nil {
+		return 0, false
+	}
+	switch {
+	case got < want:
+		return -1, true
+	case got > want:
+		return 1, true
+	}
+	return 0, true
+}
+
+// ParseDateExpr parses the date operand of a condition: "now", "today"
+// (midnight), either followed by an offset such as "-1y" or "+7d", an offset
+// alone relative to now, e.g. "-90d", or an absolute date in any form
+// ParseDate accepts.
+func ParseDateExpr(expr string, now time.Time) (time.Time, bool) {
+	expr = strings.TrimSpace(expr)
+	base := now
+	switch {
+	case strings.HasPrefix(expr, "now"):
+		expr = strings.TrimPrefix(expr, "now")
+	case strings.HasPrefix(expr, "today"):
+		y, m, d := now.Date()
+		base = time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+		expr = strings.TrimPrefix(expr, "today")
+	case strings.HasPrefix(expr, "-") || strings.HasPrefix(expr, "+"):
+	default:
+		return ParseDate(expr)
+	}
+	// Offsets may be spaced, e.g. "now - 1y"; absolute dates keep their
+	// spaces, e.g. "2023-01-02 10:00:00"
+	expr = strings.ReplaceAll(expr, " ", "")
+	if expr == "" {
+		return base, true
+	}
+	if !strings.HasPrefix(expr, "-") && !strings.HasPrefix(expr, "+") {
+		return time.Time{}, false
+	}
+	t, err := ShiftDate(base, expr)
+	return t, err == nil
+}
+
 // flattenToStrings converts a slice of interfaces to a slice of strings.
 func flattenToStrings(v interface{}) []string {
 	if v == nil {
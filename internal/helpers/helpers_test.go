@@ -8,6 +8,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/pelletier/go-toml/v2"
 	"gopkg.in/yaml.v3"
@@ -360,3 +361,283 @@ categories:
 		t.Errorf("Real-world YAML categories should be inline, got: %s", realWorldStr)
 	}
 }
+
+// TestJoinFrontmatter tests that JoinFrontmatter reverses SplitFrontmatter.
+func TestJoinFrontmatter(t *testing.T) {
+	inputs := []string{
+		"---\ntitle: Test\n---\nBody text\n",
+		"+++\ntitle = 'Test'\n+++\n\nBody text\n",
+		"{\n \"title\": \"Test\"\n}\nBody text\n",
+	}
+
+	for _, input := range inputs {
+		delim, fm, body := SplitFrontmatter([]byte(input))
+		if got := string(JoinFrontmatter(delim, fm, body)); got != input {
+			t.Errorf("JoinFrontmatter round trip = %q; want %q", got, input)
+		}
+	}
+}
+
+// TestResolveDateFields tests resolving date fields through a frontmatter mapping.
+func TestResolveDateFields(t *testing.T) {
+	front := map[string]interface{}{"pubdate": "2023-01-01", "title": "T"}
+	mapping := map[string][]string{"date": {"pubdate", "date"}}
+
+	resolved := ResolveDateFields(front, mapping)
+	if resolved["date"] != "2023-01-01" {
+		t.Errorf("expected date from pubdate, got %v", resolved["date"])
+	}
+	if _, ok := front["date"]; ok {
+		t.Errorf("original frontmatter must not be modified")
+	}
+}
+
+// TestIsContentFile tests matching content files by extension.
+func TestIsContentFile(t *testing.T) {
+	if !IsContentFile("post.md", nil) || IsContentFile("post.html", nil) {
+		t.Errorf("expected markdown-only default")
+	}
+	if !IsContentFile("post.html", []string{".md", ".html"}) {
+		t.Errorf("expected .html to be content")
+	}
+}
+
+// TestParseDate tests reading the date forms found in frontmatter.
+func TestParseDate(t *testing.T) {
+	want := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, v := range []interface{}{
+		"2024-03-01",
+		"2024-03-01T00:00:00Z",
+		"2024-03-01 00:00:00",
+		"Mar 1, 2024",
+		"2024-03-01T01:00:00+01:00",
+		want,
+		toml.LocalDate{Year: 2024, Month: 3, Day: 1},
+	} {
+		got, ok := ParseDate(v)
+		if !ok || !got.Equal(want) {
+			t.Errorf("ParseDate(%#v) = %v, %v; want %v", v, got, ok, want)
+		}
+	}
+	if _, ok := ParseDate("yesterday"); ok {
+		t.Errorf("expected an invalid date to be rejected")
+	}
+}
+
+// TestCheckConditionAt tests date and number comparisons, including relative dates.
+func TestCheckConditionAt(t *testing.T) {
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+	front := map[string]interface{}{
+		"date":        "2023-01-01",
+		"lastmod":     "2025-06-01T08:00:00+02:00",
+		"publishDate": toml.LocalDateTime{LocalDate: toml.LocalDate{Year: 2025, Month: 7, Day: 1}},
+		"expiryDate":  time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC),
+		"weight":      10,
+	}
+
+	tests := []struct {
+		cond  string
+		match bool
+	}{
+		{"date < now-1y", true},
+		{"date < now-3y", false},
+		{"date>2022-12-31", true},
+		{"date <= 2023-01-01", true},
+		{"date >= '2023-01-02'", false},
+		{"lastmod within 30d", true},
+		{"lastmod within 7d", false},
+		{"publishDate > now", true},
+		{"publishDate > today+30d", false},
+		{"expiryDate <= today", true},
+		{"expiryDate < today", false},
+		{"date > -90d", false},
+		{"date < now - 1y", true},
+		{"lastmod > 2025-06-01 05:00:00", true},
+		{"lastmod > 2025-06-01 07:00:00", false},
+		{"lastmod > '2025-06-01 05:00:00'", true},
+		{"weight >= 10", true},
+		{"weight < 5", false},
+		{"missing < now", false},
+		{"date < soon", false},
+	}
+	for _, tt := range tests {
+		if got := CheckConditionAt(front, tt.cond, now); got != tt.match {
+			t.Errorf("CheckConditionAt(%q) = %v; want %v", tt.cond, got, tt.match)
+		}
+	}
+
+	if !EvaluateConditionsAt(front, "date < now-2y AND lastmod within 30d", now) {
+		t.Errorf("expected combined relative conditions to match")
+	}
+}
+
+// TestShiftDate tests moving dates by relative offsets.
+func TestShiftDate(t *testing.T) {
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		offset string
+		want   time.Time
+	}{
+		{"2y", time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)},
+		{"-90d", time.Date(2023, 12, 2, 12, 0, 0, 0, time.UTC)},
+		{"+1m", time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)},
+		{"-2w", time.Date(2024, 2, 16, 12, 0, 0, 0, time.UTC)},
+		{"6h", time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ShiftDate(base, tt.offset)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("ShiftDate(%q) = %v, %v; want %v", tt.offset, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"", "d", "2x", "--2d", "1.5y"} {
+		if _, err := ShiftDate(base, bad); err == nil {
+			t.Errorf("ShiftDate(%q): expected an error", bad)
+		}
+	}
+}
+
+// TestDateValue tests that dates are written as native dates in every format.
+func TestDateValue(t *testing.T) {
+	d := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		layout string
+		yaml   string
+		toml   string
+		json   string
+	}{
+		{time.RFC3339, "date: 2024-03-01T09:30:00Z\n", "date = 2024-03-01T09:30:00Z\n", `"date": "2024-03-01T09:30:00Z"`},
+		{"2006-01-02", "date: 2024-03-01\n", "date = 2024-03-01\n", `"date": "2024-03-01"`},
+		{"2006-01-02T15:04:05", "date: 2024-03-01T09:30:00\n", "date = 2024-03-01T09:30:00\n", `"date": "2024-03-01T09:30:00"`},
+		{"Jan 2, 2006", `date: "Mar 1, 2024"`, `date = "Mar 1, 2024"`, `"date": "Mar 1, 2024"`},
+	}
+	for _, tt := range tests {
+		front := map[string]interface{}{"date": DateValue(d, tt.layout)}
+		for delim, want := range map[string]string{"---": tt.yaml, "+++": tt.toml, "{": tt.json} {
+			out, err := MarshalFrontmatter(delim, front)
+			if err != nil {
+				t.Fatalf("MarshalFrontmatter(%q) error: %v", delim, err)
+			}
+			if !strings.Contains(string(out), want) {
+				t.Errorf("layout %q, %s: got %q; want it to contain %q", tt.layout, delim, out, want)
+			}
+		}
+	}
+}
+
+// TestMarshalFrontmatter_NestedMaps tests writing maps and lists of maps.
+func TestMarshalFrontmatter_NestedMaps(t *testing.T) {
+	front := map[string]interface{}{
+		"title":     "T",
+		"params":    map[interface{}]interface{}{"author": "Me"},
+		"resources": []interface{}{map[string]interface{}{"src": "*.jpg", "title": "Photo"}},
+		"weight":    1,
+	}
+	yamlOut, err := MarshalFrontmatter("---", front)
+	if err != nil {
+		t.Fatalf("MarshalFrontmatter error: %v", err)
+	}
+	if want := "params:\n  author: Me\nresources:\n- src: '*.jpg'\n  title: Photo\n"; !strings.Contains(string(yamlOut), want) {
+		t.Errorf("YAML: got %q; want it to contain %q", yamlOut, want)
+	}
+
+	tomlOut, err := MarshalFrontmatter("+++", front)
+	if err != nil {
+		t.Fatalf("MarshalFrontmatter error: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := toml.Unmarshal(tomlOut, &decoded); err != nil {
+		t.Fatalf("invalid TOML %q: %v", tomlOut, err)
+	}
+	if decoded["title"] != "T" || decoded["weight"] != int64(1) {
+		t.Errorf("expected plain keys to stay outside tables, got %q", tomlOut)
+	}
+	if list, ok := decoded["resources"].([]interface{}); !ok || len(list) != 1 {
+		t.Errorf("expected an array of tables, got %q", tomlOut)
+	}
+}
+
+// TestLookup tests reading top-level keys and dotted paths.
+func TestLookup(t *testing.T) {
+	front := map[string]interface{}{
+		"title":    "T",
+		"params":   map[interface{}]interface{}{"author": map[interface{}]interface{}{"name": "Ann"}},
+		"og.image": "literal",
+	}
+	tests := []struct {
+		key  string
+		want interface{}
+		ok   bool
+	}{
+		{"title", "T", true},
+		{"params.author.name", "Ann", true},
+		{"og.image", "literal", true},
+		{"params.missing", nil, false},
+		{"title.sub", nil, false},
+	}
+	for _, tt := range tests {
+		got, ok := Lookup(front, tt.key)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("Lookup(%q) = %v, %v; want %v, %v", tt.key, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// TestSetPathAndDeletePath tests writing and removing dotted keys.
+func TestSetPathAndDeletePath(t *testing.T) {
+	front := map[string]interface{}{
+		"params":   map[interface{}]interface{}{"author": "Ann", "tags": "x"},
+		"og.image": "a.png",
+	}
+	SetPath(front, "params.author", "Bob")
+	SetPath(front, "seo.title", "T")
+	SetPath(front, "og.image", "b.png")
+	DeletePath(front, "params.tags")
+	want := map[string]interface{}{
+		"params":   map[string]interface{}{"author": "Bob"},
+		"seo":      map[string]interface{}{"title": "T"},
+		"og.image": "b.png",
+	}
+	if !reflect.DeepEqual(front, want) {
+		t.Errorf("front = %v; want %v", front, want)
+	}
+}
+
+// TestSplitFrontmatter_Unclosed tests that a block that is never closed is
+// not split, and is reported by UnclosedFrontmatter.
+func TestSplitFrontmatter_Unclosed(t *testing.T) {
+	for _, input := range []string{"---\ntitle: A\n", "+++\ntitle = \"A\"\n"} {
+		if delim, fm, body := SplitFrontmatter([]byte(input)); delim != "" || fm != nil || string(body) != input {
+			t.Errorf("SplitFrontmatter(%q) = %q, %q, %q; want no frontmatter", input, delim, fm, body)
+		}
+		if got := UnclosedFrontmatter([]byte(input)); got != input[:3] {
+			t.Errorf("UnclosedFrontmatter(%q) = %q; want %q", input, got, input[:3])
+		}
+	}
+	if got := UnclosedFrontmatter([]byte("---\ntitle: A\n---\n")); got != "" {
+		t.Errorf("expected a closed block not to be reported, got %q", got)
+	}
+}
+
+// TestErrorLine tests locating parse errors in the document.
+func TestErrorLine(t *testing.T) {
+	tests := []struct {
+		delimiter string
+		fm        string
+		want      int
+	}{
+		{YamlDelimiter, "title: A\ntags: [a\n", 3},
+		{TomlDelimiter, "title = \"A\"\nbad = \n", 3},
+		{JsonDelimiter, "{\n  \"title\": \"A\",\n  \"x\": tru\n}", 3},
+		{JsonDelimiter, "{\n  \"title\": [1,\n}", 3},
+	}
+	for _, tt := range tests {
+		_, err := UnmarshalFrontmatter(tt.delimiter, []byte(tt.fm))
+		if err == nil {
+			t.Fatalf("expected %q to fail to parse", tt.fm)
+		}
+		if got := ErrorLine(tt.delimiter, []byte(tt.fm), err); got != tt.want {
+			t.Errorf("ErrorLine(%s, %q) = %d; want %d (%v)", tt.delimiter, tt.fm, got, tt.want, err)
+		}
+	}
+}
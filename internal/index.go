@@ -0,0 +1,200 @@
+package internal
+
+import (
+	"io/fs"
+	pathpkg "path"
+	"strings"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/archetype"
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/bundle"
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/cascade"
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/helpers"
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/translation"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/site"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/vfs"
+)
+
+// siteIndex holds what a pre-pass over every content file learned, for
+// operations that need to look beyond the file being processed.
+type siteIndex struct {
+	// fronts maps each path to its parsed frontmatter, or nil if it has none.
+	fronts       map[string]map[string]interface{}
+	translations *translation.Index
+	// matchedGroups holds the translation keys of groups with a page matching cfg.Condition.
+	matchedGroups map[string]bool
+	// cascades maps a language and content-relative section directory to the
+	// cascade rules of its _index file.
+	cascades map[string][]cascade.Rule
+	// cascadeFiles maps the keys of cascades to the _index file defining them.
+	cascadeFiles map[string]string
+	// archetypes maps an archetype kind and extension to the frontmatter the
+	// archetype defines, or nil when the site has none.
+	archetypes map[string]map[string]interface{}
+	// bundles maps the index file of each leaf bundle to its resource files,
+	// relative to the bundle directory.
+	bundles map[string][]string
+}
+
+// needsIndex reports whether cfg uses an operation that requires a pre-pass.
+func needsIndex(cfg config.Config) bool {
+	return cfg.AllTranslations || cfg.Untranslated || cfg.Effective ||
+		cfg.AddResources || (cfg.Lint && (len(cfg.SharedFields) > 0 || cfg.LintArchetypes))
+}
+
+// siteOf returns the site configuration of cfg, or Hugo's defaults.
+func siteOf(cfg config.Config) *site.Site {
+	if cfg.Site != nil {
+		return cfg.Site
+	}
+	return site.Default()
+}
+
+// buildIndex parses every file in paths and indexes it. Files that fail to
+// parse are left out; the main pass reports their errors.
+func buildIndex(cfg config.Config, fsys vfs.FS, paths []string) (*siteIndex, error) {
+	s := siteOf(cfg)
+	dirs := append([]string{cfg.ContentDir}, cfg.ContentDirs...)
+	idx := &siteIndex{
+		fronts:        map[string]map[string]interface{}{},
+		translations:  translation.NewIndex(s),
+		matchedGroups: map[string]bool{},
+		cascades:      map[string][]cascade.Rule{},
+		cascadeFiles:  map[string]string{},
+		archetypes:    map[string]map[string]interface{}{},
+		bundles:       map[string][]string{},
+	}
+
+	err := ProcessFiles(cfg.Jobs, paths, func(path string) FileResult {
+		res := FileResult{Path: path}
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			res.Err = err
+			return res
+		}
+		delimiter, fmData, _ := helpers.SplitFrontmatter(data)
+		if delimiter == "" {
+			return res
+		}
+		res.Delimiter = delimiter
+		res.Front, res.Err = helpers.UnmarshalFrontmatter(delimiter, fmData)
+		return res
+	}, func(res FileResult) error {
+		if res.Err != nil {
+			return nil
+		}
+		idx.fronts[res.Path] = res.Front
+		idx.translations.Add(translation.Identify(s, dirs, res.Path, res.Front))
+		return nil
+	})
+	if err != nil {
+		return idx, err
+	}
+
+	for path, front := range idx.fronts {
+		page, _ := idx.translations.Page(path)
+		if raw, ok := front["cascade"]; ok && cascade.PageOf(page.Rel, page.Lang).Kind != "page" {
+			key := cascadeKey(page.Lang, pathpkg.Dir(page.Rel))
+			idx.cascades[key] = cascade.Parse(raw)
+			idx.cascadeFiles[key] = path
+		}
+	}
+
+	if cfg.Lint && cfg.LintArchetypes {
+		for path, front := range idx.fronts {
+			key := idx.archetypeKey(path, front)
+			if _, ok := idx.archetypes[key]; ok {
+				continue
+			}
+			kind, ext, _ := strings.Cut(key, "|")
+			fields, err := archetype.Fields(fsys, s, kind, ext)
+			if err != nil {
+				return idx, err
+			}
+			idx.archetypes[key] = fields
+		}
+	}
+
+	if cfg.AddResources {
+		for path := range idx.fronts {
+			if !bundle.IsLeafIndex(path) {
+				continue
+			}
+			files, err := bundleFiles(cfg, fsys, path)
+			if err != nil {
+				return idx, err
+			}
+			idx.bundles[path] = files
+		}
+	}
+
+	if cfg.Condition != "" {
+		for path, front := range idx.fronts {
+			if helpers.EvaluateConditionsAt(frontView(cfg, idx, path, front), cfg.Condition, now(cfg)) {
+				idx.matchedGroups[idx.translations.Group(path)] = true
+			}
+		}
+	}
+	return idx, nil
+}
+
+// effective returns a copy of front with the values cascaded to the page at
+// path from the _index files of its ancestor sections.
+func (idx *siteIndex) effective(path string, front map[string]interface{}) map[string]interface{} {
+	page, ok := idx.translations.Page(path)
+	if !ok {
+		return front
+	}
+	var levels [][]cascade.Rule
+	for _, dir := range cascade.Ancestors(page.Rel) {
+		if rules, ok := idx.cascades[cascadeKey(page.Lang, dir)]; ok {
+			levels = append(levels, rules)
+		}
+	}
+	return cascade.Apply(front, cascade.PageOf(page.Rel, page.Lang), levels)
+}
+
+// cascadeSource returns the _index file whose cascade gives the page at path
+// the value of field, or "" when no cascade does. Like effective, the
+// nearest section wins.
+func (idx *siteIndex) cascadeSource(path, field string) string {
+	page, ok := idx.translations.Page(path)
+	if !ok {
+		return ""
+	}
+	p := cascade.PageOf(page.Rel, page.Lang)
+	for _, dir := range cascade.Ancestors(page.Rel) {
+		key := cascadeKey(page.Lang, dir)
+		for _, rule := range idx.cascades[key] {
+			if _, ok := rule.Values[field]; ok && rule.Applies(p) {
+				return idx.cascadeFiles[key]
+			}
+		}
+	}
+	return ""
+}
+
+// archetypeKey identifies the archetype of the page at path: its type, or
+// else its section, and its file extension.
+func (idx *siteIndex) archetypeKey(path string, front map[string]interface{}) string {
+	page, _ := idx.translations.Page(path)
+	kind := archetype.SectionOf(page.Rel)
+	if t, ok := front["type"].(string); ok && t != "" {
+		kind = t
+	}
+	return kind + "|" + pathpkg.Ext(path)
+}
+
+// cascadeKey identifies the section directory dir in language lang.
+func cascadeKey(lang, dir string) string {
+	if dir == "." {
+		dir = ""
+	}
+	return lang + "|" + dir
+}
+
+// groupMatched reports whether any translation of the page at path matched the condition.
+func (idx *siteIndex) groupMatched(path string) bool {
+	group := idx.translations.Group(path)
+	return group != "" && idx.matchedGroups[group]
+}
@@ -0,0 +1,280 @@
+package internal
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/archetype"
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/git"
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/helpers"
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/report"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/vfs"
+)
+
+// InitOptions selects the frontmatter RunInit adds to files lacking it.
+type InitOptions struct {
+	// Format is the frontmatter format: "yaml", "toml" or "json".
+	Format string
+	// Template is the path of a file whose frontmatter seeds every block.
+	// It is rendered like an archetype, so it may use {{ .Name }} and
+	// {{ .Date }}.
+	Template string
+	// Derive lists the fields derived from each file, overriding the
+	// template: "title", from the first heading or else the file name, and
+	// "date".
+	Derive []string
+	// DateFrom is where a derived date comes from: "git", the commit that
+	// added the file, falling back to "mtime", its modification time.
+	DateFrom string
+	// List only lists the files without frontmatter.
+	List bool
+}
+
+// InitFormats maps the formats init writes to their delimiters.
+var InitFormats = map[string]string{
+	"yaml": helpers.YamlDelimiter,
+	"toml": helpers.TomlDelimiter,
+	"json": helpers.JsonDelimiter,
+}
+
+// gitAddedDate is git.AddedDate, replaced in tests.
+var gitAddedDate = git.AddedDate
+
+// headingPattern matches an ATX heading and captures its text.
+var headingPattern = regexp.MustCompile(`^#{1,6}\s+(.*?)(?:\s+#+)?\s*$`)
+
+// RunInit finds the content files without frontmatter and adds a block in
+// opts.Format to those matching cfg.Condition, which is evaluated against
+// the new frontmatter. With opts.List the files are only listed.
+func RunInit(cfg config.Config, fsys vfs.FS, opts InitOptions) (*report.Result, error) {
+	start := time.Now()
+	res := &report.Result{DryRun: cfg.DryRun}
+	delimiter, ok := InitFormats[opts.Format]
+	if !ok {
+		return res, fmt.Errorf("invalid format %q: use yaml, toml or json", opts.Format)
+	}
+	for _, field := range opts.Derive {
+		if field != "title" && field != "date" {
+			return res, fmt.Errorf("cannot derive %q: use title or date", field)
+		}
+	}
+	if opts.DateFrom != "git" && opts.DateFrom != "mtime" {
+		return res, fmt.Errorf("invalid date source %q: use git or mtime", opts.DateFrom)
+	}
+	var tmpl []byte
+	if opts.Template != "" {
+		var err error
+		if tmpl, err = os.ReadFile(opts.Template); err != nil {
+			return res, err
+		}
+	}
+	paths, err := listContent(cfg, fsys)
+	if err != nil {
+		return res, err
+	}
+
+	prepare := func(path string) FileResult {
+		return prepareInit(cfg, fsys, opts, delimiter, tmpl, path)
+	}
+	if opts.List {
+		return res, listMissing(cfg, paths, prepare)
+	}
+
+	if cfg.GcMsg == "" {
+		cfg.GcMsg = "chore: add missing frontmatter"
+	}
+	emit := emitResult(cfg, fsys, res)
+	err = ProcessFiles(cfg.Jobs, paths, prepare, func(r FileResult) error {
+		// Files that already have frontmatter are not part of the run
+		if r.Err == nil && !r.Matched && r.Skip == "" {
+			res.Record(r.Stats)
+			return nil
+		}
+		return emit(r)
+	})
+	if err != nil {
+		if cfg.Report {
+			res.Elapsed = time.Since(start)
+			_ = writeReport(cfg, res)
+		}
+		return res, err
+	}
+	return res, finishRun(cfg, res, start)
+}
+
+// listMissing prints the files among paths that prepare finds without
+// frontmatter.
+func listMissing(cfg config.Config, paths []string, prepare func(string) FileResult) error {
+	var missing []string
+	err := ProcessFiles(cfg.Jobs, paths, prepare, func(r FileResult) error {
+		if r.Err != nil {
+			return &FileError{Path: r.Path, Line: r.Line, Err: r.Err}
+		}
+		if r.Stats.NoFrontmatter > 0 {
+			missing = append(missing, r.Path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(missing) == 0 {
+		fmt.Println("✅ Every content file has frontmatter.")
+		return nil
+	}
+	fmt.Printf("📄 %s without frontmatter:\n", plural(len(missing), "file"))
+	for _, p := range missing {
+		fmt.Printf("- %s\n", p)
+	}
+	return nil
+}
+
+// prepareInit reads the file at path from fsys and, if it has no
+// frontmatter, builds the block to add in the format of delimiter.
+func prepareInit(cfg config.Config, fsys vfs.FS, opts InitOptions, delimiter string, tmpl []byte, path string) FileResult {
+	r := FileResult{Path: path}
+	r.Stats.Processed++
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		r.Err = err
+		return r
+	}
+	if unclosed := helpers.UnclosedFrontmatter(data); unclosed != "" {
+		r.Err = fmt.Errorf("frontmatter opened with %s is never closed", unclosed)
+		r.Line = 1
+		r.Stats.ParseErrors++
+		return r
+	}
+	if d, _, _ := helpers.SplitFrontmatter(data); d != "" {
+		return r
+	}
+	r.Stats.NoFrontmatter++
+	if opts.List {
+		return r
+	}
+
+	front, err := initFront(cfg, fsys, opts, tmpl, path, data)
+	if err != nil {
+		r.Err = err
+		return r
+	}
+	if cfg.Condition != "" && !helpers.EvaluateConditionsAt(front, cfg.Condition, now(cfg)) {
+		r.Skip = "does not match condition"
+		r.Stats.Skipped++
+		return r
+	}
+	r.Matched = true
+	r.Stats.Matched++
+
+	fm, err := helpers.MarshalFrontmatterOrdered(delimiter, front, fieldOrder(cfg))
+	if err != nil {
+		r.Err = err
+		return r
+	}
+	r.Delimiter = delimiter
+	r.Updated = fm
+	r.Body = data
+	r.Front = front
+	r.Operations = []string{"init"}
+	return r
+}
+
+// initFront returns the frontmatter for the file at path with the body
+// data: the frontmatter of the rendered template, if any, with the values
+// derived from the file.
+func initFront(cfg config.Config, fsys vfs.FS, opts InitOptions, tmpl []byte, path string, data []byte) (map[string]interface{}, error) {
+	date, hasDate := initDate(cfg, fsys, opts.DateFrom, path)
+	t := now(cfg)
+	if hasDate {
+		t = date
+	}
+	_, rel := splitContentPath(cfg, path)
+	page := archetype.NewData(siteOf(cfg), rel, "", t)
+
+	front := map[string]interface{}{}
+	if tmpl != nil {
+		out, err := archetype.Render(opts.Template, tmpl, page)
+		if err != nil {
+			return nil, err
+		}
+		delimiter, fm, _ := helpers.SplitFrontmatter(out)
+		if delimiter == "" {
+			return nil, fmt.Errorf("template %s has no frontmatter", opts.Template)
+		}
+		if front, err = helpers.UnmarshalFrontmatter(delimiter, fm); err != nil {
+			return nil, fmt.Errorf("template %s: %v", opts.Template, err)
+		}
+	}
+
+	for _, field := range opts.Derive {
+		switch field {
+		case "title":
+			if title := firstHeading(data); title != "" {
+				front["title"] = title
+			} else {
+				front["title"] = archetype.Title(page.Name)
+			}
+		case "date":
+			if hasDate {
+				front["date"] = helpers.DateValue(date, time.RFC3339)
+			}
+		}
+	}
+	return front, nil
+}
+
+// initDate returns the creation date of the file at path: the date of the
+// commit that added it when from is "git" and content is read from the
+// working copy, or else its modification time. It reports false when
+// neither is known.
+func initDate(cfg config.Config, fsys vfs.FS, from, path string) (time.Time, bool) {
+	if from == "git" && cfg.Source == "" {
+		if t, ok := gitAddedDate(path); ok {
+			return t, true
+		}
+	}
+	info, err := fs.Stat(fsys, path)
+	if err != nil || info.ModTime().IsZero() {
+		return time.Time{}, false
+	}
+	return info.ModTime(), true
+}
+
+// firstHeading returns the text of the first ATX or setext heading in the
+// markdown document data, ignoring fenced code blocks, or "" if it has none.
+func firstHeading(data []byte) string {
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	fence := ""
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+		if m := headingPattern.FindStringSubmatch(trimmed); m != nil && m[1] != "" {
+			return m[1]
+		}
+		if trimmed != "" && i+1 < len(lines) && isSetextUnderline(lines[i+1]) {
+			return trimmed
+		}
+	}
+	return ""
+}
+
+// isSetextUnderline reports whether line underlines a level 1 setext
+// heading.
+func isSetextUnderline(line string) bool {
+	line = strings.TrimSpace(line)
+	return line != "" && strings.Trim(line, "=") == ""
+}
@@ -0,0 +1,90 @@
+// Package internal_test contains unit tests for the internal package.
+package internal
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/vfs"
+)
+
+// TestRunInit tests adding frontmatter derived from the heading and git history.
+func TestRunInit(t *testing.T) {
+	orig := gitAddedDate
+	defer func() { gitAddedDate = orig }()
+	gitAddedDate = func(path string) (time.Time, bool) {
+		return time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC), path == "content/posts/hello.md"
+	}
+
+	fsys := vfs.NewMemFS(map[string][]byte{
+		"content/posts/hello.md":   []byte("```\n# Code\n```\n\n# Hello, World\n\nText\n"),
+		"content/posts/my-page.md": []byte("Just text\n"),
+		"content/posts/done.md":    []byte("---\ntitle: Done\n---\nBody\n"),
+	})
+	cfg := config.Config{ContentDir: "content", Yes: true}
+	res, err := RunInit(cfg, fsys, InitOptions{Format: "yaml", Derive: []string{"title", "date"}, DateFrom: "git"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Stats.Processed != 3 || res.Stats.NoFrontmatter != 2 || res.Stats.Written != 2 {
+		t.Errorf("unexpected counts: %+v", res.Stats)
+	}
+
+	for path, want := range map[string]string{
+		"content/posts/hello.md":   "---\ntitle: \"Hello, World\"\ndate: 2024-03-01T09:00:00Z\n---\n```\n# Code\n```\n\n# Hello, World\n\nText\n",
+		"content/posts/my-page.md": "---\ntitle: \"My Page\"\n---\nJust text\n",
+		"content/posts/done.md":    "---\ntitle: Done\n---\nBody\n",
+	} {
+		got, _ := fs.ReadFile(fsys, path)
+		if string(got) != want {
+			t.Errorf("%s:\ngot  %q\nwant %q", path, got, want)
+		}
+	}
+}
+
+// TestRunInit_Template tests seeding the frontmatter from a template in another format.
+func TestRunInit_Template(t *testing.T) {
+	tmpl := filepath.Join(t.TempDir(), "default.md")
+	if err := os.WriteFile(tmpl, []byte("---\ntitle: \"{{ .Name }}\"\ndraft: true\n---\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	fsys := vfs.NewMemFS(map[string][]byte{
+		"content/a-b.md": []byte("Text\n"),
+		"content/c.md":   []byte("Text\n"),
+	})
+	cfg := config.Config{ContentDir: "content", Yes: true, Condition: "title=a-b"}
+	if _, err := RunInit(cfg, fsys, InitOptions{Format: "toml", Template: tmpl, DateFrom: "mtime"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, _ := fs.ReadFile(fsys, "content/a-b.md")
+	if want := "+++\ndraft = true\ntitle = \"a-b\"\n+++\nText\n"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, _ := fs.ReadFile(fsys, "content/c.md"); string(got) != "Text\n" {
+		t.Errorf("expected content/c.md, which does not match, to be left alone, got %q", got)
+	}
+
+	if _, err := RunInit(cfg, fsys, InitOptions{Format: "xml", DateFrom: "git"}); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
+
+// TestFirstHeading tests finding the title of a markdown document.
+func TestFirstHeading(t *testing.T) {
+	tests := map[string]string{
+		"# Title\n":                  "Title",
+		"## Closed ##\n":             "Closed",
+		"Intro\n\nSetext\n=====\n":   "Setext",
+		"~~~\n# Fenced\n~~~\n#Tag\n": "",
+		"":                           "",
+	}
+	for in, want := range tests {
+		if got := firstHeading([]byte(in)); got != want {
+			t.Errorf("firstHeading(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/helpers"
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/permalink"
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/report"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/vfs"
+)
+
+// now returns the reference time of the run: cfg.Now, or else the current
+// time, to the second.
+func now(cfg config.Config) time.Time {
+	if !cfg.Now.IsZero() {
+		return cfg.Now
+	}
+	return time.Now().Truncate(time.Second)
+}
+
+// lifecycleMatches reports whether cfg.Lifecycle applies to the page with
+// the frontmatter view. Only expire is restricted: to pages dated before
+// now shifted by cfg.OlderThan.
+func lifecycleMatches(cfg config.Config, view map[string]interface{}) bool {
+	if cfg.Lifecycle != "expire" || cfg.OlderThan == "" {
+		return true
+	}
+	cutoff, err := helpers.ShiftDate(now(cfg), cfg.OlderThan)
+	if err != nil {
+		return false
+	}
+	date, ok := helpers.ParseDate(view["date"])
+	return ok && date.Before(cutoff)
+}
+
+// applyLifecycle applies cfg.Lifecycle to front: publish clears draft and
+// sets publishDate if unset, schedule sets publishDate to cfg.ScheduleAt and
+// clears draft, and expire sets expiryDate if unset. It reports whether it
+// changed anything.
+func applyLifecycle(cfg config.Config, front map[string]interface{}) bool {
+	changed := false
+	set := func(key string, value interface{}, onlyIfUnset bool) {
+		if old, ok := front[key]; ok && (onlyIfUnset || fmt.Sprintf("%v", old) == fmt.Sprintf("%v", value)) {
+			return
+		}
+		front[key] = value
+		changed = true
+	}
+	clearDraft := func() {
+		if _, ok := front["draft"]; ok {
+			set("draft", false, false)
+		}
+	}
+
+	switch cfg.Lifecycle {
+	case "publish":
+		clearDraft()
+		set("publishDate", now(cfg), true)
+	case "schedule":
+		clearDraft()
+		set("publishDate", cfg.ScheduleAt, false)
+	case "expire":
+		set("expiryDate", now(cfg), true)
+	}
+	return changed
+}
+
+// RunStatus reports the pages matching cfg.Condition that are drafts,
+// scheduled for the future or expired as of cfg.Now. Dates are read through
+// the site's frontmatter date mappings.
+func RunStatus(cfg config.Config, fsys vfs.FS) (*report.Result, error) {
+	res := &report.Result{Status: map[string][]string{}}
+	paths, views, err := matchingViews(cfg, fsys)
+	if err != nil {
+		return res, err
+	}
+	t := now(cfg)
+	s := siteOf(cfg)
+	for i, path := range paths {
+		for _, state := range pageStates(helpers.ResolveDateFields(views[i], s.DateFields), t) {
+			res.Status[state] = append(res.Status[state], path)
+		}
+	}
+	report.PrintStatus(res)
+	return res, nil
+}
+
+// pageStates returns the publishing states of the page with the resolved
+// frontmatter front at time t: "draft", "future" and "expired".
+func pageStates(front map[string]interface{}, t time.Time) []string {
+	var states []string
+	if fmt.Sprintf("%v", front["draft"]) == "true" {
+		states = append(states, "draft")
+	}
+	if d, ok := helpers.ParseDate(front["publishDate"]); ok && d.After(t) {
+		states = append(states, "future")
+	}
+	if d, ok := helpers.ParseDate(front["expiryDate"]); ok && !d.After(t) {
+		states = append(states, "expired")
+	}
+	return states
+}
+
+// RunArchive moves the pages matching cfg.Condition into the section named
+// section of their content directory, keeping their path below it, and adds
+// their old URL to their aliases. Page bundles are moved with their
+// resources; use --all-translations to move every translation of a bundle.
+func RunArchive(cfg config.Config, fsys vfs.FS, section string) (*report.Result, error) {
+	start := time.Now()
+	res := &report.Result{DryRun: cfg.DryRun}
+	if cfg.Condition == "" {
+		return res, errors.New("archive requires --if to select the pages to move")
+	}
+	section = strings.Trim(path.Clean("/"+section), "/")
+	if section == "" {
+		return res, errors.New("archive requires a section name")
+	}
+	paths, err := listContent(cfg, fsys)
+	if err != nil {
+		return res, err
+	}
+	var idx *siteIndex
+	if needsIndex(cfg) {
+		if idx, err = buildIndex(cfg, fsys, paths); err != nil {
+			return res, err
+		}
+	}
+
+	err = ProcessFiles(cfg.Jobs, paths, func(p string) FileResult {
+		return prepareFile(cfg, fsys, idx, p)
+	}, func(r FileResult) error {
+		res.Record(r.Stats)
+		return keepGoing(cfg, archiveFile(cfg, fsys, idx, section, r, res))
+	})
+	if err != nil {
+		res.Elapsed = time.Since(start)
+		return res, err
+	}
+	return res, finishRun(cfg, res, start)
+}
+
+// archiveFile moves the prepared file r into section if it matched, recording
+// what it did in res. Files that cannot be parsed, moved or written are
+// recorded as failed and returned as a FileError.
+func archiveFile(cfg config.Config, fsys vfs.FS, idx *siteIndex, section string, r FileResult, res *report.Result) error {
+	fail := func(line int, err error) error {
+		res.AddFile(report.FileAction{File: r.Path, Action: report.ActionFailed, Line: line, Reason: err.Error()})
+		return &FileError{Path: r.Path, Line: line, Err: err}
+	}
+	if r.Err != nil {
+		return fail(r.Line, r.Err)
+	}
+	root, rel := splitContentPath(cfg, r.Path)
+	if !r.Matched {
+		res.AddFile(report.FileAction{File: r.Path, Action: report.ActionSkipped, Reason: r.Skip})
+		return nil
+	}
+	if strings.HasPrefix(rel, section+"/") {
+		res.AddFile(report.FileAction{File: r.Path, Action: report.ActionUnchanged})
+		return nil
+	}
+	dest := path.Join(root, section, rel)
+	if _, err := fs.Stat(fsys, dest); err == nil {
+		return fail(0, fmt.Errorf("cannot archive to %s: it already exists", dest))
+	}
+
+	s := siteOf(cfg)
+	front := r.Front
+	oldURL := permalink.URL(s, pageOf(cfg, idx, r.Path, front))
+	addAlias(front, oldURL, permalink.URL(s, pageOf(cfg, nil, dest, front)))
+	fm, err := helpers.MarshalFrontmatterOrdered(r.Delimiter, front, fieldOrder(cfg))
+	if err != nil {
+		return fail(0, err)
+	}
+
+	fmt.Printf("📦 Archive %s → %s (alias %s)\n", r.Path, dest, oldURL)
+	archived := []string{"archive"}
+	if cfg.DryRun {
+		res.AddFile(report.FileAction{File: r.Path, Action: report.ActionDryRun, Operations: archived})
+		return nil
+	}
+	if !cfg.Yes {
+		ok, err := confirm(r.Path)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Printf("Skipping %s\n", r.Path)
+			res.AddFile(report.FileAction{File: r.Path, Action: report.ActionDeclined})
+			return nil
+		}
+	}
+
+	if err := fsys.WriteFile(dest, helpers.JoinFrontmatter(r.Delimiter, fm, r.Body), 0600); err != nil {
+		return fail(0, err)
+	}
+	if err := vfs.Remove(fsys, r.Path); err != nil {
+		return fail(0, err)
+	}
+	moved, err := moveBundleResources(cfg, fsys, r.Path, path.Dir(dest))
+	if err != nil {
+		return fail(0, err)
+	}
+	res.AddFile(report.FileAction{File: r.Path, Action: report.ActionWritten, Operations: archived})
+	res.AddModified(r.Path)
+	res.AddModified(dest)
+	for _, p := range moved {
+		res.AddModified(p)
+	}
+	return nil
+}
+
+// splitContentPath returns the content directory holding the file at p and
+// the path of the file relative to it.
+func splitContentPath(cfg config.Config, p string) (string, string) {
+	root := ""
+	for _, dir := range append([]string{cfg.ContentDir}, cfg.ContentDirs...) {
+		dir = path.Clean(dir)
+		if strings.HasPrefix(p, dir+"/") && len(dir) > len(root) {
+			root = dir
+		}
+	}
+	return root, strings.TrimPrefix(p, root+"/")
+}
+
+// moveBundleResources moves the resources of the leaf bundle whose index
+// file was at p into destDir and removes the directories left empty.
+// Content files, such as translations of the index, are left in place. It
+// returns the old and new paths of the files moved, and does nothing when p
+// is not a bundle index.
+func moveBundleResources(cfg config.Config, fsys vfs.FS, p, destDir string) ([]string, error) {
+	base := strings.SplitN(path.Base(p), ".", 2)[0]
+	if base != "index" {
+		return nil, nil
+	}
+	dir := path.Dir(p)
+	var files, dirs []string
+	err := fs.WalkDir(fsys, dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			dirs = append(dirs, name)
+		case !helpers.IsContentFile(name, contentExtensions(cfg)):
+			files = append(files, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var moved []string
+	for _, name := range files {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return moved, err
+		}
+		target := path.Join(destDir, strings.TrimPrefix(name, dir+"/"))
+		if err := fsys.WriteFile(target, data, 0600); err != nil {
+			return moved, err
+		}
+		if err := vfs.Remove(fsys, name); err != nil {
+			return moved, err
+		}
+		moved = append(moved, name, target)
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		// Directories still holding files, such as translations, stay
+		_ = vfs.Remove(fsys, dirs[i])
+	}
+	return moved, nil
+}
@@ -0,0 +1,142 @@
+// Package internal_test contains unit tests for the internal package.
+package internal
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/report"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/vfs"
+)
+
+// refNow is the fixed reference time used by the lifecycle tests.
+var refNow = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// TestApplyLifecycle tests the publish, schedule and expire operations.
+func TestApplyLifecycle(t *testing.T) {
+	at := time.Date(2025, 2, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		cfg     config.Config
+		front   map[string]interface{}
+		want    map[string]interface{}
+		changed bool
+	}{
+		{
+			config.Config{Lifecycle: "publish", Now: refNow},
+			map[string]interface{}{"draft": true},
+			map[string]interface{}{"draft": false, "publishDate": refNow},
+			true,
+		},
+		{
+			config.Config{Lifecycle: "publish", Now: refNow},
+			map[string]interface{}{"draft": false, "publishDate": "2024-01-01"},
+			map[string]interface{}{"draft": false, "publishDate": "2024-01-01"},
+			false,
+		},
+		{
+			config.Config{Lifecycle: "schedule", ScheduleAt: at},
+			map[string]interface{}{"draft": true, "publishDate": "2024-01-01"},
+			map[string]interface{}{"draft": false, "publishDate": at},
+			true,
+		},
+		{
+			config.Config{Lifecycle: "expire", Now: refNow},
+			map[string]interface{}{"title": "T"},
+			map[string]interface{}{"title": "T", "expiryDate": refNow},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		if changed := applyLifecycle(tt.cfg, tt.front); changed != tt.changed || !reflect.DeepEqual(tt.front, tt.want) {
+			t.Errorf("%s: got %v (changed %v); want %v (changed %v)", tt.cfg.Lifecycle, tt.front, changed, tt.want, tt.changed)
+		}
+	}
+}
+
+// TestRunToolFS_Expire tests expiring only pages older than the given age.
+func TestRunToolFS_Expire(t *testing.T) {
+	fsys := vfs.NewMemFS(map[string][]byte{
+		"content/old.md": []byte("---\ntitle: Old\ndate: 2022-06-01\n---\n"),
+		"content/new.md": []byte("---\ntitle: New\ndate: 2024-06-01\n---\n"),
+	})
+	cfg := config.Config{ContentDir: "content", Lifecycle: "expire", OlderThan: "-2y", Now: refNow, Yes: true}
+	res, err := captureRun(t, cfg, fsys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"content/old.md"}; !reflect.DeepEqual(res.ModifiedFiles, want) {
+		t.Errorf("ModifiedFiles = %v; want %v", res.ModifiedFiles, want)
+	}
+	old, _ := fsys.ReadFile("content/old.md")
+	if !strings.Contains(string(old), "expiryDate: 2025-01-01T12:00:00Z") {
+		t.Errorf("expected expiryDate to be set, got %q", old)
+	}
+}
+
+// TestRunStatus tests classifying drafts, scheduled and expired pages.
+func TestRunStatus(t *testing.T) {
+	fsys := vfs.NewMemFS(map[string][]byte{
+		"content/draft.md":   []byte("---\ntitle: D\ndraft: true\n---\n"),
+		"content/future.md":  []byte("+++\ntitle = \"F\"\ndate = 2025-03-01T10:00:00Z\n+++\n"),
+		"content/expired.md": []byte("{\n  \"title\": \"E\",\n  \"expiryDate\": \"2024-12-31\"\n}\n"),
+		"content/live.md":    []byte("---\ntitle: L\ndate: 2024-01-01\n---\n"),
+	})
+	cfg := config.Config{ContentDir: "content", Now: refNow}
+
+	var res *report.Result
+	silenceStdout(t, func() {
+		r, err := RunStatus(cfg, fsys)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		res = r
+	})
+	want := map[string][]string{
+		"draft":   {"content/draft.md"},
+		"future":  {"content/future.md"},
+		"expired": {"content/expired.md"},
+	}
+	if !reflect.DeepEqual(res.Status, want) {
+		t.Errorf("Status = %v; want %v", res.Status, want)
+	}
+}
+
+// TestRunArchive tests moving a bundle into the archive section with an alias.
+func TestRunArchive(t *testing.T) {
+	fsys := vfs.NewMemFS(map[string][]byte{
+		"content/posts/a/index.md":  []byte("---\ntitle: A\nold: true\n---\nBody\n"),
+		"content/posts/a/cover.jpg": []byte("jpg"),
+		"content/posts/b.md":        []byte("---\ntitle: B\n---\n"),
+	})
+	cfg := config.Config{ContentDir: "content", Condition: "old=true", Yes: true}
+
+	var err error
+	silenceStdout(t, func() { _, err = RunArchive(cfg, fsys, "archive") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	moved, err := fsys.ReadFile("content/archive/posts/a/index.md")
+	if err != nil {
+		t.Fatalf("expected the page to be moved: %v", err)
+	}
+	if want := "---\ntitle: A\naliases: [/posts/a/]\nold: true\n---\nBody\n"; string(moved) != want {
+		t.Errorf("got %q; want %q", moved, want)
+	}
+	if _, err := fsys.ReadFile("content/archive/posts/a/cover.jpg"); err != nil {
+		t.Errorf("expected the bundle resource to be moved: %v", err)
+	}
+	for _, gone := range []string{"content/posts/a/index.md", "content/posts/a/cover.jpg"} {
+		if _, err := fsys.ReadFile(gone); err == nil {
+			t.Errorf("expected %s to be removed", gone)
+		}
+	}
+
+	silenceStdout(t, func() { _, err = RunArchive(config.Config{ContentDir: "content"}, fsys, "archive") })
+	if err == nil {
+		t.Error("expected archive without --if to fail")
+	}
+}
@@ -0,0 +1,335 @@
+// Package permalink computes the URLs Hugo generates for pages and checks a
+// site for URL and alias collisions.
+package permalink
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/helpers"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/site"
+)
+
+// Page is a content file as URL computation sees it.
+type Page struct {
+	// Path is the file path.
+	Path string
+	// Rel is the path relative to the content directory without its language
+	// suffix and extension, e.g. "posts/hello/index".
+	Rel   string
+	Lang  string
+	Front map[string]interface{}
+}
+
+// URL returns the relative permalink Hugo generates for p: its url field, or
+// else the permalink pattern configured for its section, or else its path
+// with the last element replaced by its slug. Non-default languages are
+// prefixed with their language code.
+func URL(s *site.Site, p Page) string {
+	if u := stringField(p.Front, "url"); u != "" {
+		return Normalize(u)
+	}
+
+	dir, base := path.Split(p.Rel)
+	dir = strings.TrimSuffix(dir, "/")
+	var u string
+	switch {
+	case base == "_index":
+		u = "/" + dir
+	case s.Permalinks[section(p.Rel)] != "" && section(p.Rel) != "":
+		u = expand(s.Permalinks[section(p.Rel)], p)
+	default:
+		name := base
+		if base == "index" {
+			dir, name = path.Split(dir)
+			dir = strings.TrimSuffix(dir, "/")
+		}
+		if slug := stringField(p.Front, "slug"); slug != "" {
+			name = slug
+		}
+		u = path.Join("/", dir, name)
+	}
+	return Normalize(languagePrefix(s, p.Lang) + urlize(u))
+}
+
+// Aliases returns the aliases of p as normalised absolute paths. Relative
+// aliases are resolved against the directory of the page, as Hugo does.
+func Aliases(s *site.Site, p Page) []string {
+	var out []string
+	for _, alias := range stringList(p.Front["aliases"]) {
+		alias = strings.TrimSpace(alias)
+		if alias == "" {
+			continue
+		}
+		if !strings.HasPrefix(alias, "/") {
+			alias = path.Join(path.Dir(strings.TrimSuffix(URL(s, p), "/")), alias)
+		}
+		out = append(out, Normalize(alias))
+	}
+	return out
+}
+
+// Normalize returns u as an absolute path with a trailing slash, unless its
+// last element has a file extension, e.g. "/posts/a/" or "/old/page.html".
+func Normalize(u string) string {
+	u = strings.TrimSpace(u)
+	if i := strings.IndexAny(u, "?#"); i >= 0 {
+		u = u[:i]
+	}
+	u = path.Clean("/" + u)
+	u = strings.TrimSuffix(u, "/index.html")
+	if u == "/" || u == "" {
+		return "/"
+	}
+	if path.Ext(u) != "" {
+		return u
+	}
+	return u + "/"
+}
+
+// Issue is a URL problem found by Check.
+type Issue struct {
+	// Kind is "duplicate", "duplicate-alias", "alias-shadows-page" or "alias-to-unpublished".
+	Kind string
+	URL  string
+	// Paths lists the files involved, sorted.
+	Paths []string
+}
+
+// String describes the issue.
+func (i Issue) String() string {
+	switch i.Kind {
+	case "duplicate":
+		return fmt.Sprintf("%s is the URL of %d pages: %s", i.URL, len(i.Paths), strings.Join(i.Paths, ", "))
+	case "duplicate-alias":
+		return fmt.Sprintf("alias %s is declared by %d pages: %s", i.URL, len(i.Paths), strings.Join(i.Paths, ", "))
+	case "alias-shadows-page":
+		return fmt.Sprintf("alias %s of %s shadows page %s", i.URL, i.Paths[0], strings.Join(i.Paths[1:], ", "))
+	case "alias-to-unpublished":
+		return fmt.Sprintf("alias %s of %s points nowhere: the page is not published", i.URL, i.Paths[0])
+	}
+	return fmt.Sprintf("%s: %s (%s)", i.Kind, i.URL, strings.Join(i.Paths, ", "))
+}
+
+// Check reports pages sharing a URL, aliases declared by more than one page,
+// aliases that shadow a page's URL and aliases of pages that are not
+// published, which redirect to nothing. Issues are sorted by URL.
+func Check(s *site.Site, pages []Page) []Issue {
+	urls := map[string][]string{}
+	aliases := map[string][]string{}
+	var issues []Issue
+
+	for _, p := range pages {
+		urls[URL(s, p)] = append(urls[URL(s, p)], p.Path)
+	}
+	for _, p := range pages {
+		published := Published(p.Front)
+		for _, alias := range dedupe(Aliases(s, p)) {
+			aliases[alias] = append(aliases[alias], p.Path)
+			if owners, ok := urls[alias]; ok {
+				issues = append(issues, Issue{Kind: "alias-shadows-page", URL: alias, Paths: append([]string{p.Path}, sorted(owners)...)})
+			}
+			if !published {
+				issues = append(issues, Issue{Kind: "alias-to-unpublished", URL: alias, Paths: []string{p.Path}})
+			}
+		}
+	}
+	for u, paths := range urls {
+		if len(paths) > 1 {
+			issues = append(issues, Issue{Kind: "duplicate", URL: u, Paths: sorted(paths)})
+		}
+	}
+	for u, paths := range aliases {
+		if len(paths) > 1 {
+			issues = append(issues, Issue{Kind: "duplicate-alias", URL: u, Paths: sorted(paths)})
+		}
+	}
+
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].URL != issues[j].URL {
+			return issues[i].URL < issues[j].URL
+		}
+		if issues[i].Kind != issues[j].Kind {
+			return issues[i].Kind < issues[j].Kind
+		}
+		return issues[i].Paths[0] < issues[j].Paths[0]
+	})
+	return issues
+}
+
+// Published reports whether Hugo renders a page with front: it is not a
+// draft, not headless and its build options do not disable rendering.
+func Published(front map[string]interface{}) bool {
+	if isTrue(front["draft"]) || isTrue(front["headless"]) {
+		return false
+	}
+	for _, key := range []string{"build", "_build"} {
+		build, ok := helpers.NormalizeValue(front[key]).(map[string]interface{})
+		if !ok {
+			continue
+		}
+		switch v := build["render"].(type) {
+		case bool:
+			return v
+		case string:
+			return v != "never" && v != "false"
+		}
+	}
+	return true
+}
+
+// expand fills in the tokens of a permalink pattern such as "/:year/:slug/".
+func expand(pattern string, p Page) string {
+	date, hasDate := helpers.ParseDate(p.Front["date"])
+	dir, base := path.Split(p.Rel)
+	dir = strings.TrimSuffix(dir, "/")
+	filename := base
+	if base == "index" {
+		filename = path.Base(dir)
+	}
+	slug := stringField(p.Front, "slug")
+	sections := strings.Split(dir, "/")
+	if base == "index" {
+		sections = sections[:len(sections)-1]
+	}
+
+	tokens := map[string]string{
+		"year":                  date.Format("2006"),
+		"month":                 date.Format("01"),
+		"monthname":             strings.ToLower(date.Format("January")),
+		"day":                   date.Format("02"),
+		"weekday":               fmt.Sprint(int(date.Weekday())),
+		"weekdayname":           strings.ToLower(date.Format("Monday")),
+		"yearday":               fmt.Sprint(date.YearDay()),
+		"section":               section(p.Rel),
+		"sections":              strings.Join(sections, "/"),
+		"title":                 stringField(p.Front, "title"),
+		"slug":                  firstNonEmpty(slug, stringField(p.Front, "title")),
+		"slugorfilename":        firstNonEmpty(slug, filename),
+		"slugorcontentbasename": firstNonEmpty(slug, filename),
+		"filename":              filename,
+		"contentbasename":       filename,
+	}
+	if !hasDate {
+		for _, key := range []string{"year", "month", "monthname", "day", "weekday", "weekdayname", "yearday"} {
+			tokens[key] = ""
+		}
+	}
+
+	var out strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != ':' {
+			out.WriteByte(pattern[i])
+			continue
+		}
+		j := i + 1
+		for j < len(pattern) && unicode.IsLetter(rune(pattern[j])) {
+			j++
+		}
+		if v, ok := tokens[pattern[i+1:j]]; ok {
+			out.WriteString(v)
+			i = j - 1
+			continue
+		}
+		out.WriteByte(':')
+	}
+	return out.String()
+}
+
+// languagePrefix returns the URL prefix of lang: "" for the default language
+// unless defaultContentLanguageInSubdir is set, else "/<lang>".
+func languagePrefix(s *site.Site, lang string) string {
+	if len(s.Languages) == 0 || lang == "" {
+		return ""
+	}
+	if lang == s.DefaultContentLanguage && !isTrue(s.Raw["defaultcontentlanguageinsubdir"]) {
+		return ""
+	}
+	return "/" + lang
+}
+
+// section returns the top-level section of rel, or "" for top-level pages.
+func section(rel string) string {
+	if i := strings.Index(rel, "/"); i >= 0 {
+		return rel[:i]
+	}
+	return ""
+}
+
+// urlize makes u a URL path as Hugo does: lower-cased, with spaces replaced
+// by hyphens and punctuation other than / . _ - # + ~ removed.
+func urlize(u string) string {
+	var out strings.Builder
+	for _, r := range strings.ToLower(u) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("/._-#+~", r):
+			out.WriteRune(r)
+		case unicode.IsSpace(r):
+			out.WriteRune('-')
+		}
+	}
+	return out.String()
+}
+
+// stringField returns front[key] as a string, or "" when it is not set.
+func stringField(front map[string]interface{}, key string) string {
+	if v, ok := front[key]; ok && v != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return ""
+}
+
+// stringList converts a string or list value to a slice of strings.
+func stringList(v interface{}) []string {
+	switch val := v.(type) {
+	case string:
+		return []string{val}
+	case []interface{}:
+		var out []string
+		for _, item := range val {
+			out = append(out, fmt.Sprintf("%v", item))
+		}
+		return out
+	case []string:
+		return val
+	}
+	return nil
+}
+
+// isTrue reports whether v is the boolean true or the string "true".
+func isTrue(v interface{}) bool {
+	return fmt.Sprintf("%v", v) == "true"
+}
+
+// firstNonEmpty returns the first of values that is not empty.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// sorted returns a sorted copy of items.
+func sorted(items []string) []string {
+	out := append([]string(nil), items...)
+	sort.Strings(out)
+	return out
+}
+
+// dedupe removes repeated items, keeping the first occurrence.
+func dedupe(items []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, item := range items {
+		if !seen[item] {
+			seen[item] = true
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,87 @@
+// Package permalink_test contains unit tests for the permalink package.
+package permalink
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/site"
+)
+
+// TestURL tests computing permalinks from paths, slugs, url and patterns.
+func TestURL(t *testing.T) {
+	s := site.FromMap(map[string]interface{}{
+		"defaultContentLanguage": "en",
+		"languages":              map[string]interface{}{"en": map[string]interface{}{}, "de": map[string]interface{}{}},
+		"permalinks":             map[string]interface{}{"posts": "/:year/:month/:slug/"},
+	})
+
+	tests := []struct {
+		page Page
+		want string
+	}{
+		{Page{Rel: "_index", Lang: "en"}, "/"},
+		{Page{Rel: "docs/_index", Lang: "en"}, "/docs/"},
+		{Page{Rel: "docs/Getting Started", Lang: "en"}, "/docs/getting-started/"},
+		{Page{Rel: "docs/install/index", Lang: "en"}, "/docs/install/"},
+		{Page{Rel: "docs/install/index", Lang: "en", Front: map[string]interface{}{"slug": "setup"}}, "/docs/setup/"},
+		{Page{Rel: "docs/a", Lang: "de"}, "/de/docs/a/"},
+		{Page{Rel: "about", Lang: "en", Front: map[string]interface{}{"url": "/About-Us"}}, "/About-Us/"},
+		{Page{Rel: "posts/a", Lang: "en", Front: map[string]interface{}{"title": "Hello, World!", "date": "2024-03-05"}}, "/2024/03/hello-world/"},
+		{Page{Rel: "posts/b/index", Lang: "en", Front: map[string]interface{}{"slug": "bee", "date": "2023-12-01T10:00:00Z"}}, "/2023/12/bee/"},
+	}
+	for _, tt := range tests {
+		if got := URL(s, tt.page); got != tt.want {
+			t.Errorf("URL(%+v) = %q; want %q", tt.page, got, tt.want)
+		}
+	}
+}
+
+// TestAliases tests resolving absolute and relative aliases.
+func TestAliases(t *testing.T) {
+	p := Page{Rel: "docs/a", Front: map[string]interface{}{"aliases": []interface{}{"/old/a", "b", "/legacy.html", ""}}}
+	got := Aliases(site.Default(), p)
+	want := []string{"/old/a/", "/docs/b/", "/legacy.html"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Aliases() = %v; want %v", got, want)
+	}
+}
+
+// TestCheck tests detecting duplicate URLs and conflicting aliases.
+func TestCheck(t *testing.T) {
+	pages := []Page{
+		{Path: "a.md", Rel: "a", Front: map[string]interface{}{"aliases": []interface{}{"/b/", "/old/"}}},
+		{Path: "b.md", Rel: "b"},
+		{Path: "c.md", Rel: "c", Front: map[string]interface{}{"url": "/b/"}},
+		{Path: "d.md", Rel: "d", Front: map[string]interface{}{"draft": true, "aliases": []interface{}{"/old/"}}},
+	}
+	got := Check(site.Default(), pages)
+	want := []Issue{
+		{Kind: "alias-shadows-page", URL: "/b/", Paths: []string{"a.md", "b.md", "c.md"}},
+		{Kind: "duplicate", URL: "/b/", Paths: []string{"b.md", "c.md"}},
+		{Kind: "alias-to-unpublished", URL: "/old/", Paths: []string{"d.md"}},
+		{Kind: "duplicate-alias", URL: "/old/", Paths: []string{"a.md", "d.md"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Check() = %+v; want %+v", got, want)
+	}
+}
+
+// TestPublished tests detecting pages Hugo does not render.
+func TestPublished(t *testing.T) {
+	tests := []struct {
+		front map[string]interface{}
+		want  bool
+	}{
+		{map[string]interface{}{}, true},
+		{map[string]interface{}{"draft": true}, false},
+		{map[string]interface{}{"headless": true}, false},
+		{map[string]interface{}{"build": map[interface{}]interface{}{"render": "never"}}, false},
+		{map[string]interface{}{"_build": map[string]interface{}{"render": "always"}}, true},
+	}
+	for _, tt := range tests {
+		if got := Published(tt.front); got != tt.want {
+			t.Errorf("Published(%v) = %v; want %v", tt.front, got, tt.want)
+		}
+	}
+}
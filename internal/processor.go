@@ -2,157 +2,656 @@
 package internal
 
 import (
-	"bytes"
-	"encoding/csv"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/archetype"
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/bundle"
 	"github.com/Daviey/hugo-frontmatter-toolbox/internal/git"
 	"github.com/Daviey/hugo-frontmatter-toolbox/internal/helpers"
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/permalink"
 	"github.com/Daviey/hugo-frontmatter-toolbox/internal/report"
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/schema"
 	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/vfs"
 )
 
-var extractedData []map[string]string
+// FileResult is the outcome of running a single file through the
+// read → parse → evaluate → transform → marshal pipeline.
+type FileResult struct {
+	Path      string
+	Delimiter string
+	Original  []byte
+	Updated   []byte
+	Body      []byte
+	Front     map[string]interface{}
+	Matched   bool
+	// Skip explains why a file that was read is not processed further,
+	// e.g. "no frontmatter".
+	Skip    string
+	Extract *report.Row
+	// Issues lists the problems found in the file, such as lint violations.
+	Issues []string
+	// Operations lists the operations that changed the frontmatter. They
+	// are counted when the file is written.
+	Operations []string
+	Stats      report.Counts
+	Err        error
+	// Line is the line of the file Err refers to, or 0 when it has none.
+	Line int
+}
 
-func RunTool(cfg config.Config) error {
-	info, err := os.Stat(cfg.ContentDir)
-	if os.IsNotExist(err) {
-		fmt.Printf("⚠️  Directory '%s' does not exist. Nothing to process.\n", cfg.ContentDir)
-		return nil
+// Changed reports whether the updated frontmatter differs from the original.
+func (r FileResult) Changed() bool {
+	return r.Delimiter != "" && r.Updated != nil && string(r.Original) != string(r.Updated)
+}
+
+// Content returns the full updated document, frontmatter and body.
+func (r FileResult) Content() []byte {
+	return helpers.JoinFrontmatter(r.Delimiter, r.Updated, r.Body)
+}
+
+// FileError is an error reading, parsing or writing a content file.
+type FileError struct {
+	Path string
+	// Line is the line of the file the error is on, or 0 when unknown.
+	Line int
+	Err  error
+}
+
+// Error formats the error as path:line: message.
+func (e *FileError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %v", e.Path, e.Line, e.Err)
 	}
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// RunTool processes every markdown file under cfg.ContentDir and returns
+// the statistics, modified files and extract rows of this run. Content is
+// read from the working copy, or from the archive or git revision named by
+// cfg.Source.
+func RunTool(cfg config.Config) (*report.Result, error) {
+	fsys, err := vfs.Open(cfg.Source)
 	if err != nil {
-		return err
+		return &report.Result{}, err
 	}
-	if !info.IsDir() {
-		return fmt.Errorf("'%s' is not a directory", cfg.ContentDir)
+	if c, ok := fsys.(io.Closer); ok {
+		defer c.Close()
 	}
+	return RunToolFS(cfg, fsys)
+}
 
-	err = filepath.Walk(cfg.ContentDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
+// RunToolFS is like RunTool but reads and writes content through fsys,
+// in which cfg.ContentDir is resolved.
+func RunToolFS(cfg config.Config, fsys vfs.FS) (*report.Result, error) {
+	start := time.Now()
+	res := &report.Result{DryRun: cfg.DryRun}
+	paths, err := listContent(cfg, fsys)
+	if err != nil {
+		return res, err
+	}
+
+	var idx *siteIndex
+	if needsIndex(cfg) {
+		if idx, err = buildIndex(cfg, fsys, paths); err != nil {
+			return res, err
+		}
+	}
+
+	if err := processFiles(cfg, fsys, paths, idx, res); err != nil {
+		// The report still lists the files handled before the failure
+		if cfg.Report {
+			res.Elapsed = time.Since(start)
+			_ = writeReport(cfg, res)
+		}
+		return res, err
+	}
+
+	if cfg.Untranslated {
+		res.Untranslated = idx.translations.Untranslated()
+		report.PrintUntranslated(res)
+	}
+
+	if len(cfg.ExtractKeys) > 0 {
+		if err := writeExtract(os.Stdout, cfg, res); err != nil {
+			return res, err
+		}
+		return res, failureSummary(res)
+	}
+
+	return res, finishRun(cfg, res, start)
+}
+
+// finishRun writes the report for the run started at start, commits the
+// modified files with --gc and summarises the files that failed.
+func finishRun(cfg config.Config, res *report.Result, start time.Time) error {
+	if cfg.Report {
+		res.Elapsed = time.Since(start)
+		if err := writeReport(cfg, res); err != nil {
 			return err
 		}
-		if !info.IsDir() && helpers.IsMarkdownFile(path) {
-			report.Stats.Processed++
-			return processFile(cfg, path)
+	}
+
+	if cfg.GitCommit && !cfg.DryRun && len(res.ModifiedFiles) > 0 {
+		if err := git.CommitChanges(cfg, res); err != nil {
+			return err
 		}
-		return nil
-	})
+	}
+
+	return failureSummary(res)
+}
+
+// writeReport writes the run report in cfg.ReportFormat to cfg.ReportFile,
+// or to the console when no file is set.
+func writeReport(cfg config.Config, res *report.Result) error {
+	if cfg.ReportFile == "" {
+		if cfg.ReportFormat == "" || cfg.ReportFormat == "text" {
+			report.Print(res)
+			return nil
+		}
+		return report.Write(os.Stdout, res, cfg.ReportFormat)
+	}
+	f, err := os.Create(cfg.ReportFile)
 	if err != nil {
 		return err
 	}
+	if err := report.Write(f, res, cfg.ReportFormat); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("📝 Report written to %s\n", cfg.ReportFile)
+	return nil
+}
+
+// processFiles runs paths through a pool of cfg.Jobs workers and emits the
+// results to the console in path order, recording them in run. It stops at
+// the first file that cannot be read, parsed or written unless
+// cfg.KeepGoing is set, in which case the error is printed and recorded.
+func processFiles(cfg config.Config, fsys vfs.FS, paths []string, idx *siteIndex, run *report.Result) error {
+	return ProcessFiles(cfg.Jobs, paths, func(path string) FileResult {
+		return prepareFile(cfg, fsys, idx, path)
+	}, emitResult(cfg, fsys, run))
+}
+
+// emitResult returns the function processFiles emits each prepared file
+// with: it records its statistics and applies it.
+func emitResult(cfg config.Config, fsys vfs.FS, run *report.Result) func(FileResult) error {
+	return func(res FileResult) error {
+		run.Record(res.Stats)
+		return keepGoing(cfg, applyResult(cfg, fsys, res, run))
+	}
+}
 
-	if cfg.ExtractKey != "" {
-		return outputExtract(cfg)
+// keepGoing returns err, unless it is a FileError and cfg.KeepGoing is set,
+// in which case the error is printed and processing goes on. The failed
+// file must already be recorded in the run.
+func keepGoing(cfg config.Config, err error) error {
+	var fileErr *FileError
+	if cfg.KeepGoing && errors.As(err, &fileErr) {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return nil
 	}
+	return err
+}
 
-	if cfg.Report {
-		report.Print()
+// failureSummary prints the files recorded as failed in res and returns an
+// error counting them, or nil if there are none.
+func failureSummary(res *report.Result) error {
+	failed := res.Failures()
+	if len(failed) == 0 {
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "\n❌ %s failed:\n", plural(len(failed), "file"))
+	for _, f := range failed {
+		fmt.Fprintf(os.Stderr, "- %s: %s\n", f.Location(), f.Reason)
+	}
+	return fmt.Errorf("%s could not be processed", plural(len(failed), "file"))
+}
+
+// listContent is ListContent for the CLI: a missing content directory is
+// reported and yields no files.
+func listContent(cfg config.Config, fsys vfs.FS) ([]string, error) {
+	if _, err := fs.Stat(fsys, cfg.ContentDir); errors.Is(err, fs.ErrNotExist) {
+		fmt.Printf("⚠️  Directory '%s' does not exist. Nothing to process.\n", cfg.ContentDir)
+		return nil, nil
+	}
+	return ListContent(cfg, fsys)
+}
+
+// ListContent returns the content files in cfg.ContentDir and the
+// per-language cfg.ContentDirs of fsys, in walk order. Content files are
+// those with one of the content extensions of cfg.Site, or markdown files
+// without a site.
+func ListContent(cfg config.Config, fsys fs.FS) ([]string, error) {
+	info, err := fs.Stat(fsys, cfg.ContentDir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("'%s' is not a directory", cfg.ContentDir)
+	}
+
+	var paths []string
+	for i, dir := range append([]string{cfg.ContentDir}, cfg.ContentDirs...) {
+		if i > 0 {
+			// Skip per-language directories that are missing or nested in the default one
+			if _, err := fs.Stat(fsys, dir); err != nil || containsDir(cfg.ContentDir, dir) {
+				continue
+			}
+		}
+		err = fs.WalkDir(fsys, dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && helpers.IsContentFile(path, contentExtensions(cfg)) {
+				paths = append(paths, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
+
+// ProcessFiles calls prepare for each path on a pool of jobs workers and
+// passes the results to emit in the order of paths, so output is
+// deterministic regardless of which worker finishes first. prepare must be
+// safe for concurrent use; emit is only ever called from one goroutine.
+// Processing stops at the first error returned by emit.
+func ProcessFiles(jobs int, paths []string, prepare func(path string) FileResult, emit func(FileResult) error) error {
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
 	}
 
-	if cfg.GitCommit && !cfg.DryRun && len(report.ModifiedFiles) > 0 {
-		return git.CommitChanges(cfg)
+	results := make([]chan FileResult, len(paths))
+	for i := range results {
+		results[i] = make(chan FileResult, 1)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	queue := make(chan int)
+	go func() {
+		defer close(queue)
+		for i := range paths {
+			select {
+			case queue <- i:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for w := 0; w < jobs; w++ {
+		go func() {
+			for i := range queue {
+				results[i] <- prepare(paths[i])
+			}
+		}()
 	}
 
+	for i := range paths {
+		if err := emit(<-results[i]); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func processFile(cfg config.Config, path string) error {
-	// #nosec G304 - Path is from filepath.Walk and has been validated as markdown file
-	data, err := os.ReadFile(path)
+// prepareFile reads the file at path from fsys and transforms it.
+func prepareFile(cfg config.Config, fsys vfs.FS, idx *siteIndex, path string) FileResult {
+	data, err := fs.ReadFile(fsys, path)
 	if err != nil {
-		return err
+		return FileResult{Path: path, Err: err, Stats: report.Counts{Processed: 1}}
+	}
+	return transformFile(cfg, idx, path, data)
+}
+
+// matchingViews returns the paths of the content files matching
+// cfg.Condition, in order, with their frontmatter as conditions see it.
+// Files without frontmatter are left out.
+func matchingViews(cfg config.Config, fsys vfs.FS) ([]string, []map[string]interface{}, error) {
+	paths, err := listContent(cfg, fsys)
+	if err != nil {
+		return nil, nil, err
+	}
+	var idx *siteIndex
+	if needsIndex(cfg) {
+		if idx, err = buildIndex(cfg, fsys, paths); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var matched []string
+	var views []map[string]interface{}
+	err = ProcessFiles(cfg.Jobs, paths, func(path string) FileResult {
+		return prepareView(cfg, fsys, idx, path)
+	}, func(r FileResult) error {
+		if r.Err != nil {
+			return &FileError{Path: r.Path, Line: r.Line, Err: r.Err}
+		}
+		if r.Matched {
+			matched = append(matched, r.Path)
+			views = append(views, r.Front)
+		}
+		return nil
+	})
+	return matched, views, err
+}
+
+// prepareView reads the file at path from fsys and sets its frontmatter
+// view, its body and whether it matches cfg.Condition. Files without
+// frontmatter do not match.
+func prepareView(cfg config.Config, fsys vfs.FS, idx *siteIndex, path string) FileResult {
+	r := FileResult{Path: path}
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		r.Err = err
+		return r
+	}
+	delimiter, fmData, body := helpers.SplitFrontmatter(data)
+	if delimiter == "" {
+		return r
+	}
+	front, err := helpers.UnmarshalFrontmatter(delimiter, fmData)
+	if err != nil {
+		r.Err = err
+		r.Line = helpers.ErrorLine(delimiter, fmData, err)
+		return r
 	}
+	r.Delimiter = delimiter
+	r.Body = body
+	r.Front = frontView(cfg, idx, path, front)
+	r.Matched = cfg.Condition == "" || helpers.EvaluateConditionsAt(r.Front, cfg.Condition, now(cfg))
+	return r
+}
+
+// FilePreparer returns a function reading and transforming each of the
+// content files paths of fsys as RunToolFS does, indexing them first when
+// an operation of cfg looks beyond the file being processed. Like
+// TransformFile, the function produces no output and never writes.
+func FilePreparer(cfg config.Config, fsys fs.FS, paths []string) (func(path string) FileResult, error) {
+	src := vfs.ReadOnly(fsys)
+	var idx *siteIndex
+	if needsIndex(cfg) {
+		var err error
+		if idx, err = buildIndex(cfg, src, paths); err != nil {
+			return nil, err
+		}
+	}
+	return func(path string) FileResult {
+		return prepareFile(cfg, src, idx, path)
+	}, nil
+}
+
+// TransformFile parses, evaluates and transforms the document in data
+// without producing any output, so it is safe to call from multiple workers.
+// Operations that need the rest of the site, such as Effective, are applied
+// as if the file were alone: use FilePreparer for those.
+func TransformFile(cfg config.Config, path string, data []byte) FileResult {
+	return transformFile(cfg, nil, path, data)
+}
+
+// transformFile is TransformFile with access to the site index, which is nil
+// unless an operation needs it.
+func transformFile(cfg config.Config, idx *siteIndex, path string, data []byte) FileResult {
+	res := FileResult{Path: path}
+	res.Stats.Processed++
 
 	delimiter, fmData, body := helpers.SplitFrontmatter(data)
+	if unclosed := helpers.UnclosedFrontmatter(data); unclosed != "" {
+		res.Err = fmt.Errorf("frontmatter opened with %s is never closed", unclosed)
+		res.Line = 1
+		res.Stats.ParseErrors++
+		return res
+	}
 	if delimiter == "" {
-		return nil
+		res.Skip = "no frontmatter"
+		res.Stats.NoFrontmatter++
+		return res
 	}
+	res.Delimiter = delimiter
+	res.Original = fmData
+	res.Body = body
 
 	front, err := helpers.UnmarshalFrontmatter(delimiter, fmData)
 	if err != nil {
-		return err
+		res.Err = err
+		res.Line = helpers.ErrorLine(delimiter, fmData, err)
+		res.Stats.ParseErrors++
+		return res
 	}
 
-	if cfg.ExtractKey != "" {
-		val := "<missing>"
-		if v, ok := front[cfg.ExtractKey]; ok {
-			val = fmt.Sprintf("%v", v)
+	res.Front = front
+	view := frontView(cfg, idx, path, front)
+
+	if cfg.Condition != "" && !helpers.EvaluateConditionsAt(view, cfg.Condition, now(cfg)) &&
+		!(cfg.AllTranslations && idx != nil && idx.groupMatched(path)) {
+		res.Skip = "does not match condition"
+		res.Stats.Skipped++
+		return res
+	}
+	if !lifecycleMatches(cfg, view) {
+		res.Skip = "not applicable to " + cfg.Lifecycle
+		res.Stats.Skipped++
+		return res
+	}
+	res.Matched = true
+	res.Stats.Matched++
+
+	if len(cfg.ExtractKeys) > 0 {
+		// Extract what the file says unless asked for what Hugo would use
+		if cfg.Effective {
+			res.Extract = extractRow(cfg, path, view)
+		} else {
+			res.Extract = extractRow(cfg, path, front)
 		}
-		extractedData = append(extractedData, map[string]string{
-			"file":  path,
-			"key":   cfg.ExtractKey,
-			"value": val,
-		})
-		return nil
+		return res
 	}
 
-	if cfg.Condition != "" && !helpers.EvaluateConditions(front, cfg.Condition) {
-		return nil
+	// changed records that operation op changed the frontmatter
+	modified := false
+	changed := func(op string, n int) {
+		if n > 0 {
+			modified = true
+			res.Operations = append(res.Operations, op)
+		}
+	}
+
+	var oldURL string
+	if cfg.AddAliases {
+		oldURL = permalink.URL(siteOf(cfg), pageOf(cfg, idx, path, front))
 	}
-	report.Stats.Matched++
 
 	if cfg.Lint {
-		lintAndFix(cfg, front)
+		fixed := res.Stats.LintFixed
+		res.Issues = lintAndFix(cfg, idx, path, front, view, &res.Stats)
+		changed("fix", res.Stats.LintFixed-fixed)
 	}
 
 	if cfg.SetField != "" {
 		k, v := helpers.ParseSet(cfg.SetField)
-		front[k] = v
-		report.Stats.Updated++
+		if old, ok := front[k]; !ok || !sameValue(old, v) {
+			front[k] = v
+			changed("set", 1)
+		}
+	}
+
+	if cfg.Lifecycle != "" && applyLifecycle(cfg, front) {
+		changed(cfg.Lifecycle, 1)
+	}
+
+	if cfg.DateLayout != "" {
+		ok, issues := normalizeDates(cfg, front)
+		res.Issues = append(res.Issues, issues...)
+		if ok {
+			changed("normalize-dates", 1)
+		}
+	}
+
+	if len(cfg.Coerce) > 0 {
+		n, issues := coerceFields(cfg.Coerce, front)
+		res.Issues = append(res.Issues, issues...)
+		changed("coerce", n)
 	}
 
-	updatedFront, err := helpers.MarshalFrontmatter(delimiter, front)
+	if cells, ok := cfg.Import[path]; ok {
+		n, issues := applyCells(front, cells)
+		res.Issues = append(res.Issues, issues...)
+		changed("import", n)
+	}
+
+	if cfg.AddResources && idx != nil {
+		missing := bundle.Unreferenced(bundle.Parse(front["resources"]), idx.bundles[path])
+		changed("add-resources", bundle.AddEntries(front, missing))
+	}
+
+	if cfg.AddAliases && addAlias(front, oldURL, permalink.URL(siteOf(cfg), pageOf(cfg, idx, path, front))) {
+		changed("add-aliases", 1)
+	}
+
+	// Frontmatter no operation changed is kept byte for byte, as
+	// re-marshalling may reorder keys or change quoting
+	if !modified {
+		res.Updated = fmData
+		return res
+	}
+
+	updatedFront, err := helpers.MarshalFrontmatterOrdered(delimiter, front, fieldOrder(cfg))
 	if err != nil {
-		return err
+		res.Err = err
+		return res
 	}
 
-	hasChanges := string(fmData) != string(updatedFront)
-	if hasChanges {
-		if cfg.DryRun || (!cfg.Yes && !cfg.DryRun) {
-			if err := helpers.ShowFrontmatterDiff(path, fmData, updatedFront, delimiter, cfg.DiffContext); err != nil {
-				return err
-			}
+	res.Updated = updatedFront
+	return res
+}
+
+// sameValue reports whether a and b are the same frontmatter value, such
+// as the int64 a TOML file holds and the int given on the command line.
+func sameValue(a, b interface{}) bool {
+	return schema.TypeOf(a) == schema.TypeOf(b) && fmt.Sprintf("%v", a) == fmt.Sprintf("%v", b)
+}
+
+// applyResult emits the output for a prepared file: it records extract rows,
+// shows the diff, asks for confirmation and writes the file. The action
+// taken is recorded in run.
+func applyResult(cfg config.Config, fsys vfs.FS, res FileResult, run *report.Result) error {
+	record := func(action, reason string) {
+		run.AddFile(report.FileAction{File: res.Path, Action: action, Reason: reason, Issues: res.Issues, Operations: res.Operations})
+	}
+	if res.Err != nil {
+		run.AddFile(report.FileAction{File: res.Path, Action: report.ActionFailed, Line: res.Line, Reason: res.Err.Error()})
+		return &FileError{Path: res.Path, Line: res.Line, Err: res.Err}
+	}
+	if res.Extract != nil {
+		run.AddExtract(*res.Extract)
+		return nil
+	}
+	for _, issue := range res.Issues {
+		fmt.Printf("❌ %s: %s\n", res.Path, issue)
+	}
+
+	if res.Skip != "" {
+		record(report.ActionSkipped, res.Skip)
+		return nil
+	}
+	if !res.Changed() {
+		record(report.ActionUnchanged, "")
+		return nil
+	}
+
+	if cfg.DryRun || (!cfg.Yes && !cfg.DryRun) {
+		if err := helpers.ShowFrontmatterDiff(res.Path, res.Original, res.Updated, res.Delimiter, cfg.DiffContext); err != nil {
+			return err
 		}
+	}
 
-		if !cfg.Yes && !cfg.DryRun {
-			ok, err := confirm(path)
-			if err != nil {
-				return err
-			}
-			if !ok {
-				fmt.Printf("Skipping %s\n", path)
-				return nil
-			}
+	if !cfg.Yes && !cfg.DryRun {
+		ok, err := confirm(res.Path)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Printf("Skipping %s\n", res.Path)
+			record(report.ActionDeclined, "")
+			return nil
 		}
-	} else {
-		return nil
 	}
 
 	if cfg.DryRun {
+		record(report.ActionDryRun, "")
 		return nil
 	}
 
-	var buf bytes.Buffer
-	if delimiter == "{" {
-		buf.Write(updatedFront)
-	} else {
-		buf.WriteString(delimiter + "\n")
-		buf.Write(updatedFront)
-		buf.WriteString(delimiter + "\n")
+	if err := fsys.WriteFile(res.Path, res.Content(), 0600); err != nil {
+		record(report.ActionFailed, err.Error())
+		return &FileError{Path: res.Path, Err: err}
 	}
-	buf.Write(body)
+	run.AddModified(res.Path)
+	record(report.ActionWritten, "")
+	return nil
+}
 
-	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
-		return err
+// frontView returns the frontmatter as conditions and lint see it: with
+// values cascaded from ancestor sections in --effective mode, and the dates
+// Hugo would use. Extraction only sees it in --effective mode. Edits always
+// go to the literal frontmatter.
+func frontView(cfg config.Config, idx *siteIndex, path string, front map[string]interface{}) map[string]interface{} {
+	view := front
+	if cfg.Effective && idx != nil {
+		view = idx.effective(path, view)
+	}
+	if cfg.Site != nil {
+		view = helpers.ResolveDateFields(view, cfg.Site.DateFields)
+	}
+	return view
+}
+
+// contentExtensions returns the file extensions treated as content, from the
+// site configuration when there is one.
+func contentExtensions(cfg config.Config) []string {
+	if cfg.Site != nil {
+		return cfg.Site.ContentExtensions
 	}
-	report.ModifiedFiles = append(report.ModifiedFiles, path)
 	return nil
 }
 
+// fieldOrder returns the order of well-known YAML fields, from the site
+// configuration when there is one.
+func fieldOrder(cfg config.Config) []string {
+	if cfg.Site != nil {
+		return cfg.Site.FieldOrder()
+	}
+	return helpers.DefaultFieldOrder
+}
+
+// containsDir reports whether dir is parent or inside it.
+func containsDir(parent, dir string) bool {
+	rel, err := filepath.Rel(parent, dir)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, "../")
+}
+
 func confirm(path string) (bool, error) {
 	fmt.Printf("Apply changes to %s? (y/N): ", path)
 	var response string
@@ -164,47 +663,112 @@ func confirm(path string) (bool, error) {
 	return response == "y" || response == "yes", nil
 }
 
-func lintAndFix(cfg config.Config, front map[string]interface{}) {
-	hasIssue := false
+// lintAndFix checks the required and prohibited fields against view and
+// applies fixes to the literal frontmatter front. It returns the violations found.
+func lintAndFix(cfg config.Config, idx *siteIndex, path string, front, view map[string]interface{}, delta *report.Counts) []string {
+	issues := lintTranslations(cfg, idx, path, front, delta)
+	issues = append(issues, lintArchetype(cfg, idx, path, view)...)
 	for _, req := range cfg.RequiredFields {
-		if _, ok := front[req]; !ok {
-			hasIssue = true
+		if _, ok := view[req]; !ok {
+			issues = append(issues, fmt.Sprintf("missing required field '%s'", req))
 			if cfg.Fix {
 				front[req] = ""
-				report.Stats.LintFixed++
+				delta.LintFixed++
 			}
 		}
 	}
 	for _, block := range cfg.ProhibitedFields {
-		if _, ok := front[block]; ok {
-			hasIssue = true
-			if cfg.Fix {
-				delete(front, block)
-				report.Stats.LintFixed++
+		if _, ok := view[block]; !ok {
+			continue
+		}
+		if _, ok := front[block]; !ok {
+			// Deleting it here would change nothing
+			issues = append(issues, fmt.Sprintf("has prohibited field '%s' %s, which cannot be fixed in this file",
+				block, inheritedFrom(cfg, idx, path, front, block)))
+			continue
+		}
+		issues = append(issues, fmt.Sprintf("has prohibited field '%s'", block))
+		if cfg.Fix {
+			delete(front, block)
+			delta.LintFixed++
+		}
+	}
+	if len(issues) > 0 {
+		delta.LintFails++
+	}
+	return issues
+}
+
+// inheritedFrom describes where the frontmatter view of the page at path
+// gets field from when its literal frontmatter front lacks it: Hugo's date
+// mapping of another key, or the cascade of an _index file.
+func inheritedFrom(cfg config.Config, idx *siteIndex, path string, front map[string]interface{}, field string) string {
+	keys := []string{field}
+	if cfg.Site != nil && len(cfg.Site.DateFields[field]) > 0 {
+		keys = cfg.Site.DateFields[field]
+	}
+	// The first key present in the view is the one Hugo reads
+	for _, key := range keys {
+		if _, ok := front[key]; ok {
+			return fmt.Sprintf("through the date mapping of '%s'", key)
+		}
+		if cfg.Effective && idx != nil {
+			if src := idx.cascadeSource(path, key); src != "" {
+				return "from the cascade in " + src
 			}
 		}
 	}
-	if hasIssue {
-		report.Stats.LintFails++
+	return "from an inherited value"
+}
+
+// lintArchetype reports the fields the archetype of the page at path defines
+// but view lacks. They are not fixed, as archetype values are placeholders.
+func lintArchetype(cfg config.Config, idx *siteIndex, path string, view map[string]interface{}) []string {
+	if idx == nil || !cfg.LintArchetypes {
+		return nil
 	}
+	var issues []string
+	for _, key := range archetype.Missing(idx.archetypes[idx.archetypeKey(path, view)], view) {
+		issues = append(issues, fmt.Sprintf("missing field '%s' from archetype", key))
+	}
+	return issues
 }
 
-func outputExtract(cfg config.Config) error {
-	switch cfg.ExtractFormat {
-	case "json":
-		out, _ := json.MarshalIndent(extractedData, "", "  ")
-		fmt.Println(string(out))
-	case "csv":
-		writer := csv.NewWriter(os.Stdout)
-		_ = writer.Write([]string{"file", "key", "value"})
-		for _, row := range extractedData {
-			_ = writer.Write([]string{row["file"], row["key"], row["value"]})
+// lintTranslations checks that the page at path has the same cfg.SharedFields
+// as its reference translation, copying the reference values with --fix.
+// It returns the fields that differed.
+func lintTranslations(cfg config.Config, idx *siteIndex, path string, front map[string]interface{}, delta *report.Counts) []string {
+	if idx == nil || len(cfg.SharedFields) == 0 {
+		return nil
+	}
+	ref, ok := idx.translations.Reference(path)
+	if !ok || ref.Path == path {
+		return nil
+	}
+	refFront := idx.fronts[ref.Path]
+
+	var issues []string
+	// Translations at the same path should agree on translationKey; with
+	// it in --shared the mismatch is reported and fixed below
+	if page, _ := idx.translations.Page(path); page.Rel == ref.Rel && page.TranslationKey != ref.TranslationKey &&
+		!slices.Contains(cfg.SharedFields, "translationKey") {
+		issues = append(issues, fmt.Sprintf("translationKey %q differs from %q in translation %s", page.TranslationKey, ref.TranslationKey, ref.Path))
+	}
+	for _, field := range cfg.SharedFields {
+		want, wantOK := refFront[field]
+		got, gotOK := front[field]
+		if wantOK == gotOK && fmt.Sprintf("%v", want) == fmt.Sprintf("%v", got) {
+			continue
 		}
-		writer.Flush()
-	default:
-		for _, row := range extractedData {
-			fmt.Printf("%s: %s = %s\n", row["file"], row["key"], row["value"])
+		issues = append(issues, fmt.Sprintf("field '%s' differs from translation %s", field, ref.Path))
+		if cfg.Fix {
+			if wantOK {
+				front[field] = want
+			} else {
+				delete(front, field)
+			}
+			delta.LintFixed++
 		}
 	}
-	return nil
+	return issues
 }
@@ -2,16 +2,26 @@
 package internal
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/report"
 	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/site"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/vfs"
 )
 
 // TestRunTool_InvalidDir tests RunTool with an invalid content directory.
 func TestRunTool_InvalidDir(t *testing.T) {
 	cfg := config.Config{ContentDir: "invalid-dir"}
-	err := RunTool(cfg)
+	_, err := RunTool(cfg)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -27,7 +37,7 @@ func TestRunTool_NotADir(t *testing.T) {
 	}()
 
 	cfg := config.Config{ContentDir: "file.md"}
-	err := RunTool(cfg)
+	_, err := RunTool(cfg)
 	if err == nil {
 		t.Errorf("expected error for non-directory path")
 	}
@@ -46,8 +56,531 @@ func TestRunTool_EmptyDir(t *testing.T) {
 	}()
 
 	cfg := config.Config{ContentDir: "testdir"}
-	err := RunTool(cfg)
+	_, err := RunTool(cfg)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
 }
+
+// writeContent creates a content directory populated with the given files.
+func writeContent(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+// TestRunTool_ParallelDeterministic tests that parallel runs update every file
+// and record results in path order.
+func TestRunTool_ParallelDeterministic(t *testing.T) {
+	files := map[string]string{}
+	for i := 0; i < 20; i++ {
+		files[fmt.Sprintf("post-%02d.md", i)] = "---\ntitle: Post\ndraft: true\n---\nBody\n"
+	}
+	dir := writeContent(t, files)
+
+	cfg := config.Config{ContentDir: dir, SetField: "draft=false", Yes: true, Jobs: 4}
+	res, err := RunTool(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if res.Stats.Processed != 20 || res.Stats.Matched != 20 {
+		t.Errorf("unexpected stats: %+v", res.Stats)
+	}
+	if len(res.ModifiedFiles) != 20 {
+		t.Fatalf("expected 20 modified files, got %d", len(res.ModifiedFiles))
+	}
+	if !sort.StringsAreSorted(res.ModifiedFiles) {
+		t.Errorf("modified files not in path order: %v", res.ModifiedFiles)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "post-07.md"))
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if !strings.Contains(string(data), "draft: false") {
+		t.Errorf("expected draft to be updated, got: %s", data)
+	}
+}
+
+// TestRunTool_Repeated tests that consecutive runs do not share state.
+func TestRunTool_Repeated(t *testing.T) {
+	dir := writeContent(t, map[string]string{
+		"a.md": "---\ntitle: A\n---\nA\n",
+		"b.md": "---\ntitle: B\n---\nB\n",
+	})
+
+	cfg := config.Config{ContentDir: dir, ExtractKeys: []string{"title"}}
+	for i := 0; i < 2; i++ {
+		res, err := captureRun(t, cfg, vfs.OS{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Stats.Processed != 2 {
+			t.Errorf("run %d: expected 2 processed files, got %d", i, res.Stats.Processed)
+		}
+		if len(res.Extracted) != 2 || res.Extracted[0].Values["title"] != "A" {
+			t.Errorf("run %d: unexpected extract rows: %v", i, res.Extracted)
+		}
+	}
+}
+
+// captureRun runs RunToolFS with stdout discarded.
+func captureRun(t *testing.T, cfg config.Config, fsys vfs.FS) (res *report.Result, err error) {
+	t.Helper()
+	silenceStdout(t, func() { res, err = RunToolFS(cfg, fsys) })
+	return res, err
+}
+
+// silenceStdout runs f with os.Stdout redirected to the null device.
+func silenceStdout(t *testing.T, f func()) {
+	t.Helper()
+	stdout := os.Stdout
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", os.DevNull, err)
+	}
+	os.Stdout = devNull
+	defer func() {
+		os.Stdout = stdout
+		_ = devNull.Close()
+	}()
+	f()
+}
+
+// TestRunToolFS_InMemory tests running the pipeline over an in-memory file system.
+func TestRunToolFS_InMemory(t *testing.T) {
+	fsys := vfs.NewMemFS(map[string][]byte{
+		"content/a.md": []byte("---\ntitle: A\ndraft: true\n---\nA\n"),
+		"content/b.md": []byte("+++\ntitle = \"B\"\ndraft = true\n+++\nB\n"),
+	})
+
+	cfg := config.Config{ContentDir: "content", SetField: "draft=false", Yes: true}
+	res, err := RunToolFS(cfg, fsys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.ModifiedFiles) != 2 {
+		t.Errorf("expected 2 modified files, got %v", res.ModifiedFiles)
+	}
+	data, _ := fsys.ReadFile("content/b.md")
+	if !strings.Contains(string(data), "draft = false") {
+		t.Errorf("expected b.md to be updated, got %q", data)
+	}
+}
+
+// TestRunToolFS_ReadOnly tests that writes to a read-only source fail.
+func TestRunToolFS_ReadOnly(t *testing.T) {
+	fsys := vfs.ReadOnly(vfs.NewMemFS(map[string][]byte{
+		"content/a.md": []byte("---\ndraft: true\n---\n"),
+	}))
+
+	cfg := config.Config{ContentDir: "content", SetField: "draft=false", Yes: true}
+	if _, err := RunToolFS(cfg, fsys); !errors.Is(err, vfs.ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly, got %v", err)
+	}
+}
+
+// TestRunToolFS_SiteDefaults tests that site configuration drives date
+// conditions, content directories and extensions.
+func TestRunToolFS_SiteDefaults(t *testing.T) {
+	fsys := vfs.NewMemFS(map[string][]byte{
+		"content/en/old.md":       []byte("---\npubdate: 2020-01-01\n---\n"),
+		"content/de/old.markdown": []byte("---\npubdate: 2020-01-01\n---\n"),
+		"content/de/new.md":       []byte("---\npubdate: 2024-01-01\n---\n"),
+	})
+	s := site.FromMap(map[string]interface{}{
+		"contentTypes": map[string]interface{}{"text/markdown": map[string]interface{}{}},
+		"frontmatter":  map[string]interface{}{"date": []interface{}{"pubdate"}},
+	})
+
+	cfg := config.Config{
+		ContentDir:  "content/en",
+		ContentDirs: []string{"content/de", "content/missing"},
+		Condition:   "date<2021-01-01",
+		SetField:    "draft=true",
+		Yes:         true,
+		Site:        s,
+	}
+	res, err := RunToolFS(cfg, fsys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"content/en/old.md", "content/de/old.markdown"}
+	if !reflect.DeepEqual(res.ModifiedFiles, want) {
+		t.Errorf("ModifiedFiles = %v; want %v", res.ModifiedFiles, want)
+	}
+}
+
+// TestRunToolFS_Translations tests propagating edits to translations and
+// fixing fields that translations must share.
+func TestRunToolFS_Translations(t *testing.T) {
+	fsys := vfs.NewMemFS(map[string][]byte{
+		"content/a.md":    []byte("---\ntitle: A\nweight: 1\ntags: [beta]\n---\n"),
+		"content/a.de.md": []byte("---\ntitle: A (de)\nweight: 5\n---\n"),
+		"content/b.md":    []byte("---\ntitle: B\nweight: 2\n---\n"),
+	})
+	s := site.FromMap(map[string]interface{}{
+		"languages": map[string]interface{}{"en": map[string]interface{}{}, "de": map[string]interface{}{}},
+	})
+
+	cfg := config.Config{
+		ContentDir:      "content",
+		Condition:       "tags contains 'beta'",
+		SetField:        "draft=true",
+		AllTranslations: true,
+		Lint:            true,
+		Fix:             true,
+		SharedFields:    []string{"weight"},
+		Untranslated:    true,
+		Yes:             true,
+		Site:            s,
+	}
+	res, err := captureRun(t, cfg, fsys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"content/a.de.md", "content/a.md"}
+	if !reflect.DeepEqual(res.ModifiedFiles, want) {
+		t.Errorf("ModifiedFiles = %v; want %v", res.ModifiedFiles, want)
+	}
+	de, _ := fsys.ReadFile("content/a.de.md")
+	if !strings.Contains(string(de), "draft: true") || !strings.Contains(string(de), "weight: 1") {
+		t.Errorf("expected German translation to be updated, got %q", de)
+	}
+	if !reflect.DeepEqual(res.Untranslated["de"], []string{"content/b.md"}) {
+		t.Errorf("unexpected untranslated pages: %v", res.Untranslated)
+	}
+}
+
+// TestRunToolFS_TranslationKey tests linting translations at the same path
+// when only one of them sets translationKey.
+func TestRunToolFS_TranslationKey(t *testing.T) {
+	files := map[string][]byte{
+		"content/hello/index.en.md": []byte("---\ntitle: Hello\ntranslationKey: hello\nweight: 1\n---\n"),
+		"content/hello/index.de.md": []byte("---\ntitle: Hallo\nweight: 2\n---\n"),
+	}
+	s := site.FromMap(map[string]interface{}{
+		"languages": map[string]interface{}{"en": map[string]interface{}{}, "de": map[string]interface{}{}},
+	})
+
+	for _, tt := range []struct {
+		shared []string
+		want   []string
+	}{
+		{[]string{"date", "weight", "translationKey"}, []string{
+			"field 'weight' differs from translation content/hello/index.en.md",
+			"field 'translationKey' differs from translation content/hello/index.en.md",
+		}},
+		{[]string{"weight"}, []string{
+			`translationKey "" differs from "hello" in translation content/hello/index.en.md`,
+			"field 'weight' differs from translation content/hello/index.en.md",
+		}},
+	} {
+		cfg := config.Config{ContentDir: "content", Lint: true, SharedFields: tt.shared, Untranslated: true, DryRun: true, Site: s}
+		res, err := captureRun(t, cfg, vfs.NewMemFS(files))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var issues []string
+		for _, f := range res.Files {
+			issues = append(issues, f.Issues...)
+		}
+		if !reflect.DeepEqual(issues, tt.want) {
+			t.Errorf("--shared %v: issues = %q; want %q", tt.shared, issues, tt.want)
+		}
+		if len(res.Untranslated) != 0 {
+			t.Errorf("expected no untranslated pages, got %v", res.Untranslated)
+		}
+	}
+}
+
+// TestRunToolFS_Effective tests evaluating conditions and lint against
+// cascaded values while writing only the literal frontmatter.
+func TestRunToolFS_Effective(t *testing.T) {
+	fsys := vfs.NewMemFS(map[string][]byte{
+		"content/_index.md":      []byte("---\ntitle: Home\ncascade:\n  author: Site\n---\n"),
+		"content/docs/_index.md": []byte("---\ntitle: Docs\ncascade:\n- draft: true\n  _target:\n    kind: page\n---\n"),
+		"content/docs/a.md":      []byte("---\ntitle: A\n---\n"),
+		"content/docs/b.md":      []byte("---\ntitle: B\ndraft: false\n---\n"),
+		"content/blog/c.md":      []byte("---\ntitle: C\n---\n"),
+	})
+
+	cfg := config.Config{
+		ContentDir:     "content",
+		Condition:      "draft=true",
+		SetField:       "reviewed=false",
+		Effective:      true,
+		Lint:           true,
+		RequiredFields: []string{"author"},
+		Yes:            true,
+	}
+	res, err := captureRun(t, cfg, fsys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := []string{"content/docs/a.md"}; !reflect.DeepEqual(res.ModifiedFiles, want) {
+		t.Errorf("ModifiedFiles = %v; want %v", res.ModifiedFiles, want)
+	}
+	a, _ := fsys.ReadFile("content/docs/a.md")
+	if got, want := string(a), "---\ntitle: A\nreviewed: false\n---\n"; got != want {
+		t.Errorf("expected only the literal frontmatter to be written, got %q; want %q", got, want)
+	}
+	if res.Stats.LintFails != 0 {
+		t.Errorf("expected the cascaded author to satisfy lint, got %d failures", res.Stats.LintFails)
+	}
+}
+
+// TestRunToolFS_Operations tests counting operations only for the files
+// written, or planned in a dry run, and not for those declined at the prompt.
+func TestRunToolFS_Operations(t *testing.T) {
+	files := map[string][]byte{
+		"content/a.md": []byte("---\ntitle: A\n---\n"),
+		"content/b.md": []byte("---\ntitle: B\n---\n"),
+	}
+	tests := []struct {
+		name  string
+		cfg   config.Config
+		input string
+		want  int
+	}{
+		{"written", config.Config{Yes: true}, "", 2},
+		{"dry run", config.Config{DryRun: true}, "", 2},
+		{"one declined", config.Config{}, "y\nn\n", 1},
+	}
+	for _, tt := range tests {
+		cfg := tt.cfg
+		cfg.ContentDir = "content"
+		cfg.SetField = "draft=true"
+		if tt.input != "" {
+			r, w, err := os.Pipe()
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, _ = w.WriteString(tt.input)
+			_ = w.Close()
+			stdin := os.Stdin
+			os.Stdin = r
+			defer func() { os.Stdin = stdin }()
+		}
+		res, err := captureRun(t, cfg, vfs.NewMemFS(files))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if got := res.Stats.Operations["set"]; got != tt.want {
+			t.Errorf("%s: set counted for %d files; want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestRunToolFS_LintInheritedProhibited tests that prohibited fields a page
+// only gets from a date mapping or a cascade are reported but not "fixed".
+func TestRunToolFS_LintInheritedProhibited(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     map[string][]byte
+		field     string
+		effective bool
+		want      string
+	}{
+		{"date mapping", map[string][]byte{
+			"content/a.md": []byte("---\ntitle: A\npublishDate: 2024-01-01\n---\n"),
+		}, "date", false, "has prohibited field 'date' through the date mapping of 'publishDate', which cannot be fixed in this file"},
+		{"cascade", map[string][]byte{
+			"content/_index.md": []byte("---\ntitle: Home\ncascade:\n  banner: big.png\n---\n"),
+			"content/a.md":      []byte("---\ntitle: A\npublishDate: 2024-01-01\n---\n"),
+		}, "banner", true, "has prohibited field 'banner' from the cascade in content/_index.md, which cannot be fixed in this file"},
+	}
+	for _, tt := range tests {
+		fsys := vfs.NewMemFS(tt.files)
+		cfg := config.Config{
+			ContentDir:       "content",
+			Lint:             true,
+			Fix:              true,
+			Yes:              true,
+			Effective:        tt.effective,
+			ProhibitedFields: []string{tt.field},
+			Site:             site.FromMap(map[string]interface{}{}),
+		}
+		res, err := captureRun(t, cfg, fsys)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if res.Stats.LintFixed != 0 || len(res.ModifiedFiles) != 0 {
+			t.Errorf("%s: expected nothing to be fixed, got %d fixes in %v", tt.name, res.Stats.LintFixed, res.ModifiedFiles)
+		}
+		a, _ := fsys.ReadFile("content/a.md")
+		if string(a) != string(tt.files["content/a.md"]) {
+			t.Errorf("%s: expected content/a.md to be left alone, got %q", tt.name, a)
+		}
+		var issues []string
+		for _, f := range res.Files {
+			issues = append(issues, f.Issues...)
+		}
+		if !reflect.DeepEqual(issues, []string{tt.want}) {
+			t.Errorf("%s: issues = %q; want %q", tt.name, issues, tt.want)
+		}
+	}
+}
+
+// TestRunToolFS_LintArchetypes tests reporting fields a page's archetype defines but it lacks.
+func TestRunToolFS_LintArchetypes(t *testing.T) {
+	fsys := vfs.NewMemFS(map[string][]byte{
+		"archetypes/posts.md": []byte("---\ntitle: \"{{ .Name }}\"\ndate: {{ .Date }}\nauthor: \"\"\n---\n"),
+		"content/posts/a.md":  []byte("---\ntitle: A\ndate: 2024-01-01\nauthor: Me\n---\n"),
+		"content/posts/b.md":  []byte("---\ntitle: B\n---\n"),
+		"content/docs/c.md":   []byte("---\ntitle: C\n---\n"),
+		"content/posts/d.md":  []byte("---\ntitle: D\ntype: docs\n---\n"),
+	})
+	cfg := config.Config{ContentDir: "content", Lint: true, LintArchetypes: true, DryRun: true}
+	res, err := captureRun(t, cfg, fsys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Stats.LintFails != 1 {
+		t.Errorf("expected only content/posts/b.md to fail, got %d failures", res.Stats.LintFails)
+	}
+}
+
+// TestRunToolFS_RelativeDates tests evaluating relative dates against cfg.Now.
+func TestRunToolFS_RelativeDates(t *testing.T) {
+	fsys := vfs.NewMemFS(map[string][]byte{
+		"content/old.md":    []byte("+++\ntitle = \"Old\"\nlastmod = 2023-05-01T10:00:00Z\n+++\n"),
+		"content/recent.md": []byte("+++\ntitle = \"Recent\"\nlastmod = 2024-12-20T10:00:00Z\n+++\n"),
+	})
+	cfg := config.Config{
+		ContentDir: "content",
+		Condition:  "lastmod < now-1y",
+		SetField:   "stale=true",
+		Now:        time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Yes:        true,
+	}
+	res, err := captureRun(t, cfg, fsys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"content/old.md"}; !reflect.DeepEqual(res.ModifiedFiles, want) {
+		t.Errorf("ModifiedFiles = %v; want %v", res.ModifiedFiles, want)
+	}
+}
+
+// TestRunToolFS_ReportFile tests recording per-file actions and writing the
+// report to a file.
+func TestRunToolFS_ReportFile(t *testing.T) {
+	fsys := vfs.NewMemFS(map[string][]byte{
+		"content/a.md": []byte("---\ntitle: A\n---\n"),
+		"content/b.md": []byte("---\ntitle: B\n---\n"),
+		"content/c.md": []byte("No frontmatter\n"),
+	})
+	file := filepath.Join(t.TempDir(), "report.json")
+	cfg := config.Config{
+		ContentDir: "content", Condition: "title=A", SetField: "draft=true", Yes: true,
+		Report: true, ReportFormat: "json", ReportFile: file,
+	}
+	res, err := captureRun(t, cfg, fsys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []report.FileAction{
+		{File: "content/a.md", Action: report.ActionWritten, Operations: []string{"set"}},
+		{File: "content/b.md", Action: report.ActionSkipped, Reason: "does not match condition"},
+		{File: "content/c.md", Action: report.ActionSkipped, Reason: "no frontmatter"},
+	}
+	if !reflect.DeepEqual(res.Files, want) {
+		t.Errorf("Files = %+v; want %+v", res.Files, want)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("expected the report to be written: %v", err)
+	}
+	if !strings.Contains(string(data), `"reason": "no frontmatter"`) {
+		t.Errorf("unexpected report: %s", data)
+	}
+}
+
+// TestRunToolFS_Stats tests counting each outcome and operation, and that
+// files no operation changes are left byte for byte.
+func TestRunToolFS_Stats(t *testing.T) {
+	files := map[string][]byte{
+		"content/a.md": []byte("+++\ntitle = \"A\"\ndraft = true\n+++\n"),
+		"content/b.md": []byte("---\ntitle: B\ndraft: false\n---\n"),
+		"content/c.md": []byte("No frontmatter\n"),
+		"content/d.md": []byte("---\ntitle: D\ntype: page\n---\n"),
+	}
+	for _, dryRun := range []bool{false, true} {
+		fsys := vfs.NewMemFS(files)
+		cfg := config.Config{ContentDir: "content", Condition: "title=A OR title=B", SetField: "draft=true", DryRun: dryRun, Yes: true}
+		res, err := captureRun(t, cfg, fsys)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := report.Counts{
+			Processed: 4, Matched: 2, Changed: 1, Written: 1, Unchanged: 1, Skipped: 1, NoFrontmatter: 1,
+			Operations: map[string]int{"set": 1},
+		}
+		if dryRun {
+			want.Written = 0
+		}
+		if !reflect.DeepEqual(res.Stats, want) || res.DryRun != dryRun {
+			t.Errorf("dry run %v: Stats = %+v; want %+v", dryRun, res.Stats, want)
+		}
+		if data, _ := fsys.ReadFile("content/a.md"); string(data) != string(files["content/a.md"]) {
+			t.Errorf("expected the unchanged TOML file to be left as it is, got %q", data)
+		}
+	}
+}
+
+// TestRunToolFS_KeepGoing tests stopping at the first broken file by
+// default, and recording it and processing the rest with KeepGoing.
+func TestRunToolFS_KeepGoing(t *testing.T) {
+	files := map[string][]byte{
+		"content/a.md": []byte("---\ntitle: A\ntags: [a\n---\n"),
+		"content/b.md": []byte("---\ntitle: B\n"),
+		"content/c.md": []byte("---\ntitle: C\n---\n"),
+	}
+
+	fsys := vfs.NewMemFS(files)
+	cfg := config.Config{ContentDir: "content", SetField: "draft=true", Yes: true, Jobs: 1}
+	_, err := captureRun(t, cfg, fsys)
+	var fileErr *FileError
+	if !errors.As(err, &fileErr) || fileErr.Path != "content/a.md" || fileErr.Line != 3 {
+		t.Fatalf("expected a parse error at content/a.md:3, got %v", err)
+	}
+	if data, _ := fsys.ReadFile("content/c.md"); strings.Contains(string(data), "draft") {
+		t.Errorf("expected the run to stop before content/c.md")
+	}
+
+	cfg.KeepGoing = true
+	var res *report.Result
+	silenceStdout(t, func() {
+		stderr := os.Stderr
+		os.Stderr = os.Stdout
+		defer func() { os.Stderr = stderr }()
+		res, err = RunToolFS(cfg, fsys)
+	})
+	if err == nil || err.Error() != "2 files could not be processed" {
+		t.Errorf("expected the failures to be counted, got %v", err)
+	}
+	if res.Stats.ParseErrors != 2 || res.Stats.Written != 1 {
+		t.Errorf("unexpected stats: %+v", res.Stats)
+	}
+	want := []string{"content/a.md:3", "content/b.md:1"}
+	var got []string
+	for _, f := range res.Failures() {
+		got = append(got, f.Location())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("failures = %v; want %v", got, want)
+	}
+}
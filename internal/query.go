@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/archetype"
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/query"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/vfs"
+)
+
+// QueryOptions selects how RunQuery writes its results.
+type QueryOptions struct {
+	// Files lists only the files for which the expression yields a value
+	// other than null or false.
+	Files bool
+	// Format is "plain", one line per result prefixed by the file, or
+	// "json".
+	Format string
+}
+
+// queryResults holds the outputs of a query for one file.
+type queryResults struct {
+	File    string        `json:"file"`
+	Results []interface{} `json:"results"`
+}
+
+// RunQuery evaluates the query expr against the frontmatter of each file
+// matching cfg.Condition, with the file's path, section and body bound to
+// $file, $section and $body, and writes the results of the files with any
+// to w.
+func RunQuery(cfg config.Config, fsys vfs.FS, expr string, opts QueryOptions, w io.Writer) error {
+	if opts.Format != "" && opts.Format != "plain" && opts.Format != "json" {
+		return fmt.Errorf("unsupported query format %q: use plain or json", opts.Format)
+	}
+	q, err := query.Parse(expr)
+	if err != nil {
+		return fmt.Errorf("invalid query: %v", err)
+	}
+	paths, err := listContent(cfg, fsys)
+	if err != nil {
+		return err
+	}
+	var idx *siteIndex
+	if needsIndex(cfg) {
+		if idx, err = buildIndex(cfg, fsys, paths); err != nil {
+			return err
+		}
+	}
+
+	files := []queryResults{}
+	err = ProcessFiles(cfg.Jobs, paths, func(path string) FileResult {
+		return prepareView(cfg, fsys, idx, path)
+	}, func(r FileResult) error {
+		if r.Err != nil {
+			return &FileError{Path: r.Path, Line: r.Line, Err: r.Err}
+		}
+		if !r.Matched {
+			return nil
+		}
+		_, rel := splitContentPath(cfg, r.Path)
+		results, err := q.Eval(r.Front, map[string]interface{}{
+			"file":    r.Path,
+			"section": archetype.SectionOf(rel),
+			// SplitFrontmatter leaves the newline closing the frontmatter on the body
+			"body": strings.TrimPrefix(strings.TrimPrefix(string(r.Body), "\r"), "\n"),
+		})
+		if err != nil {
+			return &FileError{Path: r.Path, Err: err}
+		}
+		if opts.Files && !anyTruthy(results) {
+			return nil
+		}
+		if len(results) > 0 {
+			files = append(files, queryResults{File: r.Path, Results: results})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return writeQueryResults(w, opts, files)
+}
+
+// anyTruthy reports whether any of values is other than null or false.
+func anyTruthy(values []interface{}) bool {
+	for _, v := range values {
+		if query.Truthy(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeQueryResults writes the results of each file in opts.Format.
+func writeQueryResults(w io.Writer, opts QueryOptions, files []queryResults) error {
+	if opts.Format == "json" {
+		var out interface{} = files
+		if opts.Files {
+			paths := make([]string, len(files))
+			for i, f := range files {
+				paths[i] = f.File
+			}
+			out = paths
+		}
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(data))
+		return nil
+	}
+
+	for _, f := range files {
+		if opts.Files {
+			fmt.Fprintln(w, f.File)
+			continue
+		}
+		for _, v := range f.Results {
+			text, err := compactJSON(v)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "%s: %s\n", f.File, text)
+		}
+	}
+	return nil
+}
+
+// compactJSON encodes v on one line without escaping HTML characters.
+func compactJSON(v interface{}) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
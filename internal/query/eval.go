@@ -0,0 +1,567 @@
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// env holds the frontmatter an expression is evaluated against and its
+// variables.
+type env struct {
+	root interface{}
+	vars map[string]interface{}
+}
+
+// node is a parsed expression; eval returns its outputs for the input in.
+type node interface {
+	eval(in interface{}, e *env) ([]interface{}, error)
+}
+
+type identityNode struct{}
+
+func (identityNode) eval(in interface{}, e *env) ([]interface{}, error) {
+	return []interface{}{in}, nil
+}
+
+type rootNode struct{}
+
+func (rootNode) eval(in interface{}, e *env) ([]interface{}, error) {
+	return []interface{}{e.root}, nil
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n literalNode) eval(in interface{}, e *env) ([]interface{}, error) {
+	return []interface{}{n.value}, nil
+}
+
+type varNode struct {
+	name string
+}
+
+func (n varNode) eval(in interface{}, e *env) ([]interface{}, error) {
+	v, ok := e.vars[n.name]
+	if !ok {
+		return nil, fmt.Errorf("$%s is not defined", n.name)
+	}
+	return []interface{}{v}, nil
+}
+
+// fieldNode reads key from each output of from. Values that are not
+// objects have no keys and yield null.
+type fieldNode struct {
+	from node
+	key  string
+}
+
+func (n fieldNode) eval(in interface{}, e *env) ([]interface{}, error) {
+	return each(n.from, in, e, func(v interface{}) ([]interface{}, error) {
+		m, _ := v.(map[string]interface{})
+		return []interface{}{m[n.key]}, nil
+	})
+}
+
+// indexNode indexes each output of from with each output of index,
+// evaluated against the input: a number indexes a list, counting from the
+// end when negative, and a string reads a key.
+type indexNode struct {
+	from, index node
+}
+
+func (n indexNode) eval(in interface{}, e *env) ([]interface{}, error) {
+	indexes, err := n.index.eval(in, e)
+	if err != nil {
+		return nil, err
+	}
+	return each(n.from, in, e, func(v interface{}) ([]interface{}, error) {
+		var out []interface{}
+		for _, idx := range indexes {
+			switch idx := idx.(type) {
+			case float64:
+				list, _ := v.([]interface{})
+				i := int(idx)
+				if i < 0 {
+					i += len(list)
+				}
+				if i < 0 || i >= len(list) {
+					out = append(out, nil)
+				} else {
+					out = append(out, list[i])
+				}
+			case string:
+				m, _ := v.(map[string]interface{})
+				out = append(out, m[idx])
+			default:
+				return nil, fmt.Errorf("cannot index with %s", typeName(idx))
+			}
+		}
+		return out, nil
+	})
+}
+
+// iterateNode yields the items of each list output by from, or the values
+// of each object in key order. Other values yield nothing.
+type iterateNode struct {
+	from node
+}
+
+func (n iterateNode) eval(in interface{}, e *env) ([]interface{}, error) {
+	return each(n.from, in, e, func(v interface{}) ([]interface{}, error) {
+		switch v := v.(type) {
+		case []interface{}:
+			return v, nil
+		case map[string]interface{}:
+			var out []interface{}
+			for _, k := range sortedKeys(v) {
+				out = append(out, v[k])
+			}
+			return out, nil
+		}
+		return nil, nil
+	})
+}
+
+type pipeNode struct {
+	left, right node
+}
+
+func (n pipeNode) eval(in interface{}, e *env) ([]interface{}, error) {
+	return each(n.left, in, e, func(v interface{}) ([]interface{}, error) {
+		return n.right.eval(v, e)
+	})
+}
+
+type commaNode struct {
+	left, right node
+}
+
+func (n commaNode) eval(in interface{}, e *env) ([]interface{}, error) {
+	left, err := n.left.eval(in, e)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(in, e)
+	return append(left, right...), err
+}
+
+// collectNode collects the outputs of an expression into one list.
+type collectNode struct {
+	inner node
+}
+
+func (n collectNode) eval(in interface{}, e *env) ([]interface{}, error) {
+	out, err := n.inner.eval(in, e)
+	if out == nil {
+		out = []interface{}{}
+	}
+	return []interface{}{out}, err
+}
+
+// logicNode is "and" or "or". The right side is only evaluated when the
+// left one does not decide the result.
+type logicNode struct {
+	and         bool
+	left, right node
+}
+
+func (n logicNode) eval(in interface{}, e *env) ([]interface{}, error) {
+	return each(n.left, in, e, func(l interface{}) ([]interface{}, error) {
+		if Truthy(l) != n.and {
+			return []interface{}{!n.and}, nil
+		}
+		right, err := n.right.eval(in, e)
+		out := make([]interface{}, len(right))
+		for i, r := range right {
+			out[i] = Truthy(r)
+		}
+		return out, err
+	})
+}
+
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+func (n compareNode) eval(in interface{}, e *env) ([]interface{}, error) {
+	right, err := n.right.eval(in, e)
+	if err != nil {
+		return nil, err
+	}
+	return each(n.left, in, e, func(l interface{}) ([]interface{}, error) {
+		out := make([]interface{}, len(right))
+		for i, r := range right {
+			c := compare(l, r)
+			switch n.op {
+			case "==":
+				out[i] = c == 0
+			case "!=":
+				out[i] = c != 0
+			case "<":
+				out[i] = c < 0
+			case "<=":
+				out[i] = c <= 0
+			case ">":
+				out[i] = c > 0
+			case ">=":
+				out[i] = c >= 0
+			}
+		}
+		return out, nil
+	})
+}
+
+type callNode struct {
+	name string
+	args []node
+}
+
+func (n callNode) eval(in interface{}, e *env) ([]interface{}, error) {
+	switch n.name {
+	case "empty":
+		return nil, nil
+	case "not":
+		return []interface{}{!Truthy(in)}, nil
+	case "type":
+		return []interface{}{typeName(in)}, nil
+	case "select":
+		ok, err := anyOutput(n.args[0], in, e)
+		if err != nil || !ok {
+			return nil, err
+		}
+		return []interface{}{in}, nil
+	case "map":
+		out, err := iterateNode{identityNode{}}.eval(in, e)
+		if err != nil {
+			return nil, err
+		}
+		return collectNode{pipeNode{literalsNode(out), n.args[0]}}.eval(nil, e)
+	case "any", "all":
+		return n.quantify(in, e)
+	case "length":
+		switch v := in.(type) {
+		case nil:
+			return []interface{}{0.0}, nil
+		case string:
+			return []interface{}{float64(utf8.RuneCountInString(v))}, nil
+		case []interface{}:
+			return []interface{}{float64(len(v))}, nil
+		case map[string]interface{}:
+			return []interface{}{float64(len(v))}, nil
+		case float64:
+			if v < 0 {
+				v = -v
+			}
+			return []interface{}{v}, nil
+		}
+		return nil, fmt.Errorf("%s has no length", typeName(in))
+	case "keys":
+		switch v := in.(type) {
+		case map[string]interface{}:
+			keys := []interface{}{}
+			for _, k := range sortedKeys(v) {
+				keys = append(keys, k)
+			}
+			return []interface{}{keys}, nil
+		case []interface{}:
+			keys := make([]interface{}, len(v))
+			for i := range v {
+				keys[i] = float64(i)
+			}
+			return []interface{}{keys}, nil
+		}
+		return nil, fmt.Errorf("%s has no keys", typeName(in))
+	}
+	return n.withArg(in, e)
+}
+
+// withArg evaluates the functions that take one value argument, once per
+// output of the argument.
+func (n callNode) withArg(in interface{}, e *env) ([]interface{}, error) {
+	args, err := n.args[0].eval(in, e)
+	if err != nil {
+		return nil, err
+	}
+	var out []interface{}
+	for _, arg := range args {
+		var v bool
+		switch n.name {
+		case "has":
+			switch in := in.(type) {
+			case map[string]interface{}:
+				_, v = in[fmt.Sprintf("%v", arg)]
+			case []interface{}:
+				i, ok := arg.(float64)
+				v = ok && i >= 0 && int(i) < len(in)
+			default:
+				return nil, fmt.Errorf("cannot check whether %s has a key", typeName(in))
+			}
+		case "contains":
+			v = contains(in, arg)
+		case "test", "startswith", "endswith":
+			s, ok := in.(string)
+			pattern, patternOK := arg.(string)
+			if !ok || !patternOK {
+				return nil, fmt.Errorf("%s needs strings, got %s and %s", n.name, typeName(in), typeName(arg))
+			}
+			switch n.name {
+			case "test":
+				re, err := regexp.Compile(pattern)
+				if err != nil {
+					return nil, fmt.Errorf("invalid regular expression %q: %v", pattern, err)
+				}
+				v = re.MatchString(s)
+			case "startswith":
+				v = strings.HasPrefix(s, pattern)
+			case "endswith":
+				v = strings.HasSuffix(s, pattern)
+			}
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// quantify evaluates any and all: over the items of the input list, over
+// the items of the input list mapped by a condition, or over the outputs
+// of a generator mapped by a condition.
+func (n callNode) quantify(in interface{}, e *env) ([]interface{}, error) {
+	var gen, cond node = iterateNode{identityNode{}}, identityNode{}
+	switch len(n.args) {
+	case 1:
+		cond = n.args[0]
+	case 2:
+		gen, cond = n.args[0], n.args[1]
+	}
+	items, err := gen.eval(in, e)
+	if err != nil {
+		return nil, err
+	}
+	want := n.name == "any"
+	for _, item := range items {
+		ok, err := anyOutput(cond, item, e)
+		if err != nil {
+			return nil, err
+		}
+		if ok == want {
+			return []interface{}{want}, nil
+		}
+	}
+	return []interface{}{!want}, nil
+}
+
+// literalsNode yields a fixed list of values.
+type literalsNode []interface{}
+
+func (n literalsNode) eval(in interface{}, e *env) ([]interface{}, error) {
+	return n, nil
+}
+
+// each calls f with every output of from and concatenates the results.
+func each(from node, in interface{}, e *env, f func(interface{}) ([]interface{}, error)) ([]interface{}, error) {
+	values, err := from.eval(in, e)
+	if err != nil {
+		return nil, err
+	}
+	var out []interface{}
+	for _, v := range values {
+		res, err := f(v)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, res...)
+	}
+	return out, nil
+}
+
+// anyOutput reports whether n yields a truthy value for in.
+func anyOutput(n node, in interface{}, e *env) (bool, error) {
+	out, err := n.eval(in, e)
+	if err != nil {
+		return false, err
+	}
+	for _, v := range out {
+		if Truthy(v) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Truthy reports whether v is neither null nor false.
+func Truthy(v interface{}) bool {
+	return v != nil && v != false
+}
+
+// typeName returns the JSON type of v, as the type function does.
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	}
+	return "object"
+}
+
+// typeOrder ranks the JSON types as jq sorts them.
+var typeOrder = map[string]int{"null": 0, "boolean": 1, "number": 2, "string": 3, "array": 4, "object": 5}
+
+// compare orders a and b: by type first, then by value. Lists compare item
+// by item, and objects by their sorted keys, then their values.
+func compare(a, b interface{}) int {
+	ta, tb := typeName(a), typeName(b)
+	if ta != tb {
+		return typeOrder[ta] - typeOrder[tb]
+	}
+	switch a := a.(type) {
+	case bool:
+		switch {
+		case a == b.(bool):
+			return 0
+		case a:
+			return 1
+		}
+		return -1
+	case float64:
+		switch b := b.(float64); {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(a, b.(string))
+	case []interface{}:
+		b := b.([]interface{})
+		for i := 0; i < len(a) && i < len(b); i++ {
+			if c := compare(a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+		return len(a) - len(b)
+	case map[string]interface{}:
+		b := b.(map[string]interface{})
+		ka, kb := sortedKeys(a), sortedKeys(b)
+		if c := compare(toList(ka), toList(kb)); c != 0 {
+			return c
+		}
+		for _, k := range ka {
+			if c := compare(a[k], b[k]); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+// contains reports whether a contains b: a substring of a string, items
+// each contained in an item of a list, or keys whose values are contained
+// in an object. Other values must be equal.
+func contains(a, b interface{}) bool {
+	switch a := a.(type) {
+	case string:
+		s, ok := b.(string)
+		return ok && strings.Contains(a, s)
+	case []interface{}:
+		items, ok := b.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, item := range items {
+			found := false
+			for _, v := range a {
+				if contains(v, item) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		m, ok := b.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for k, v := range m {
+			if av, ok := a[k]; !ok || !contains(av, v) {
+				return false
+			}
+		}
+		return true
+	}
+	return compare(a, b) == 0
+}
+
+// normalize converts a frontmatter value to its JSON form: maps with
+// string keys, lists, float64 numbers and strings for dates and other
+// values.
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil, bool, string, float64:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case fmt.Stringer:
+		return v.String()
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint())
+	case reflect.Float32:
+		return rv.Float()
+	case reflect.Slice, reflect.Array:
+		out := make([]interface{}, rv.Len())
+		for i := range out {
+			out[i] = normalize(rv.Index(i).Interface())
+		}
+		return out
+	case reflect.Map:
+		out := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			out[fmt.Sprintf("%v", iter.Key().Interface())] = normalize(iter.Value().Interface())
+		}
+		return out
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// toList converts keys to a list value.
+func toList(keys []string) []interface{} {
+	out := make([]interface{}, len(keys))
+	for i, k := range keys {
+		out[i] = k
+	}
+	return out
+}
@@ -0,0 +1,84 @@
+// Package query_test contains unit tests for the query package.
+package query
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// evalString evaluates expr against doc and returns its outputs as JSON.
+func evalString(t *testing.T, expr string, doc map[string]interface{}) string {
+	t.Helper()
+	q, err := Parse(expr)
+	if err != nil {
+		t.Fatalf("Parse(%q) error: %v", expr, err)
+	}
+	out, err := q.Eval(doc, map[string]interface{}{"file": "content/posts/a.md", "section": "posts"})
+	if err != nil {
+		t.Fatalf("Eval(%q) error: %v", expr, err)
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// TestEval tests evaluating expressions against frontmatter as the YAML and TOML decoders return it.
+func TestEval(t *testing.T) {
+	doc := map[string]interface{}{
+		"title":  "Go Tips",
+		"date":   time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC),
+		"weight": int64(5),
+		"draft":  false,
+		"tags":   []interface{}{"go", "golang", "web"},
+		"params": map[interface{}]interface{}{"author": "Ann"},
+		"resources": []interface{}{
+			map[interface{}]interface{}{"src": "a.jpg", "params": map[interface{}]interface{}{"credits": "Bob"}},
+			map[interface{}]interface{}{"src": "b.jpg", "params": map[interface{}]interface{}{"credits": ""}},
+			map[interface{}]interface{}{"src": "c.jpg"},
+		},
+	}
+	tests := map[string]string{
+		".title":                       `["Go Tips"]`,
+		".":                            `[{"date":"2024-03-01T09:00:00Z","draft":false,"params":{"author":"Ann"},"resources":[{"params":{"credits":"Bob"},"src":"a.jpg"},{"params":{"credits":""},"src":"b.jpg"},{"src":"c.jpg"}],"tags":["go","golang","web"],"title":"Go Tips","weight":5}]`,
+		"params.author, .missing.deep": `["Ann",null]`,
+		".title.nested, .missing[]":    `[null]`,
+		"resources[].params.credits":   `["Bob","",null]`,
+		`resources[] | select(.params.credits == null or .params.credits == "") | .src`: `["b.jpg","c.jpg"]`,
+		`any(resources[]; .params.credits == "")`:                                       `[true]`,
+		`all(resources[]; has("src"))`:                                                  `[true]`,
+		`.tags | any(. == "rust"), all(startswith("go"))`:                               `[false,false]`,
+		`[.tags[] | select(test("^go"))]`:                                               `[["go","golang"]]`,
+		".weight >= 5 and .draft == false":                                              `[true]`,
+		".weight > 5 or .date < \"2025\"":                                               `[true]`,
+		".draft | not":                                                                  `[true]`,
+		"(.tags | length), (.title | length)":                                           `[3,7]`,
+		".params | keys":                                                                `[["author"]]`,
+		".tags | contains([\"web\"])":                                                   `[true]`,
+		".title | endswith(\"Tips\")":                                                   `[true]`,
+		"[.weight, .tags, .params, null] | map(type)":                                   `[["number","array","object","null"]]`,
+		".tags[] | empty":                                                               `null`,
+		"[$file, $section]":                                                             `[["content/posts/a.md","posts"]]`,
+		".tags[0], .tags[-1], .tags[9]":                                                 `["go","web",null]`,
+	}
+	for expr, want := range tests {
+		if got := evalString(t, expr, doc); got != want {
+			t.Errorf("%s = %s; want %s", expr, got, want)
+		}
+	}
+}
+
+// TestEval_Errors tests runtime errors.
+func TestEval_Errors(t *testing.T) {
+	for _, expr := range []string{"$missing", ".draft | length", ".title | test(\"(\")", ".tags[true]"} {
+		q, err := Parse(expr)
+		if err != nil {
+			t.Fatalf("Parse(%q) error: %v", expr, err)
+		}
+		if _, err := q.Eval(map[string]interface{}{"draft": true, "title": "x", "tags": []interface{}{}}, nil); err == nil {
+			t.Errorf("expected an error evaluating %s", expr)
+		}
+	}
+}
@@ -0,0 +1,402 @@
+// Package query evaluates jq-like expressions, which also accept JSONPath
+// forms such as $.tags[*], over the frontmatter of a page.
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Query is a parsed expression.
+type Query struct {
+	root node
+}
+
+// Parse parses expr. Paths start at the frontmatter, written . or $, and
+// the leading dot may be left out: title, .params.author, resources[].src,
+// $.tags[0] and .["my key"] are all paths. Missing keys read as null, and
+// iterating over null yields nothing, so pages lacking a field need no
+// special care. Paths combine with |, comma, ==, !=, <, <=, >, >=, and,
+// or, [...] to collect outputs into a list, and the functions select, map,
+// any, all, length, keys, has, contains, test, startswith, endswith, not,
+// type and empty.
+func Parse(expr string) (*Query, error) {
+	toks, err := lex(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	root, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos+1)
+	}
+	return &Query{root: root}, nil
+}
+
+// Eval evaluates q against the frontmatter doc, with vars bound to $name,
+// and returns its outputs. Values are converted to their JSON form first:
+// numbers are float64 and dates are RFC 3339 strings.
+func (q *Query) Eval(doc map[string]interface{}, vars map[string]interface{}) ([]interface{}, error) {
+	e := &env{root: normalize(doc), vars: map[string]interface{}{}}
+	for name, v := range vars {
+		e.vars[name] = normalize(v)
+	}
+	return q.root.eval(e.root, e)
+}
+
+// tokenKind is the kind of a lexical token.
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokVar
+	tokString
+	tokNumber
+	tokOp
+)
+
+// token is a lexical token; text holds the identifier, variable name
+// without $, unquoted string or operator.
+type token struct {
+	kind tokenKind
+	text string
+	num  float64
+	pos  int
+}
+
+// operators lists the operators, longest first.
+var operators = []string{"==", "!=", "<=", ">=", "<", ">", "|", ",", ";", "(", ")", "[", "]", ".", "*", "?"}
+
+// lex splits src into tokens.
+func lex(src string) ([]token, error) {
+	var toks []token
+	isIdent := func(r rune, first bool) bool {
+		return r == '_' || unicode.IsLetter(r) || (!first && (unicode.IsDigit(r) || r == '-'))
+	}
+	for i := 0; i < len(src); {
+		r := rune(src[i])
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case isIdent(r, true) || r >= 0x80:
+			j := i
+			for j < len(src) && (isIdent(rune(src[j]), false) || src[j] >= 0x80) {
+				j++
+			}
+			toks = append(toks, token{kind: tokIdent, text: src[i:j], pos: i})
+			i = j
+		case r == '$':
+			j := i + 1
+			for j < len(src) && isIdent(rune(src[j]), j == i+1) {
+				j++
+			}
+			toks = append(toks, token{kind: tokVar, text: src[i+1 : j], pos: i})
+			i = j
+		case r == '"' || r == '\'':
+			j := i + 1
+			for j < len(src) && src[j] != src[i] {
+				if src[j] == '\\' && r == '"' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("unterminated string at position %d", i+1)
+			}
+			text := src[i+1 : j]
+			if r == '"' {
+				s, err := strconv.Unquote(src[i : j+1])
+				if err != nil {
+					return nil, fmt.Errorf("invalid string at position %d", i+1)
+				}
+				text = s
+			}
+			toks = append(toks, token{kind: tokString, text: text, pos: i})
+			i = j + 1
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(src) && unicode.IsDigit(rune(src[i+1]))):
+			j := i + 1
+			for j < len(src) && (unicode.IsDigit(rune(src[j])) || src[j] == '.') {
+				j++
+			}
+			n, err := strconv.ParseFloat(src[i:j], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q at position %d", src[i:j], i+1)
+			}
+			toks = append(toks, token{kind: tokNumber, text: src[i:j], num: n, pos: i})
+			i = j
+		default:
+			op := ""
+			for _, o := range operators {
+				if strings.HasPrefix(src[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q at position %d", r, i+1)
+			}
+			toks = append(toks, token{kind: tokOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(toks, token{kind: tokEOF, pos: len(src)}), nil
+}
+
+// functions maps the functions an expression can call to their arities.
+var functions = map[string][]int{
+	"select":     {1},
+	"map":        {1},
+	"any":        {0, 1, 2},
+	"all":        {0, 1, 2},
+	"length":     {0},
+	"keys":       {0},
+	"has":        {1},
+	"contains":   {1},
+	"test":       {1},
+	"startswith": {1},
+	"endswith":   {1},
+	"not":        {0},
+	"type":       {0},
+	"empty":      {0},
+}
+
+// parser is a recursive descent parser over the tokens of an expression.
+type parser struct {
+	toks []token
+	i    int
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+// accept consumes the next token if it is the operator or keyword text.
+func (p *parser) accept(text string) bool {
+	t := p.peek()
+	if (t.kind == tokOp || t.kind == tokIdent) && t.text == text {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(op string) error {
+	if p.accept(op) {
+		return nil
+	}
+	t := p.peek()
+	if t.kind == tokEOF {
+		return fmt.Errorf("expected %q at end of expression", op)
+	}
+	return fmt.Errorf("expected %q at position %d, got %q", op, t.pos+1, t.text)
+}
+
+// parsePipe parses a | b.
+func (p *parser) parsePipe() (node, error) {
+	left, err := p.parseComma()
+	for err == nil && p.accept("|") {
+		var right node
+		if right, err = p.parseComma(); err == nil {
+			left = pipeNode{left, right}
+		}
+	}
+	return left, err
+}
+
+// parseComma parses a, b.
+func (p *parser) parseComma() (node, error) {
+	left, err := p.parseOr()
+	for err == nil && p.accept(",") {
+		var right node
+		if right, err = p.parseOr(); err == nil {
+			left = commaNode{left, right}
+		}
+	}
+	return left, err
+}
+
+// parseOr parses a or b.
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	for err == nil && p.accept("or") {
+		var right node
+		if right, err = p.parseAnd(); err == nil {
+			left = logicNode{and: false, left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+// parseAnd parses a and b.
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseCompare()
+	for err == nil && p.accept("and") {
+		var right node
+		if right, err = p.parseCompare(); err == nil {
+			left = logicNode{and: true, left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+// parseCompare parses a comparison such as a == b.
+func (p *parser) parseCompare() (node, error) {
+	left, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.accept(op) {
+			right, err := p.parsePostfix()
+			if err != nil {
+				return nil, err
+			}
+			return compareNode{op, left, right}, nil
+		}
+	}
+	return left, nil
+}
+
+// parsePostfix parses a primary expression followed by field accesses,
+// indexes and iterations, e.g. $.resources[].params["credits"].
+func (p *parser) parsePostfix() (node, error) {
+	n, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.accept("?"):
+			// Errors are not raised by paths, so ? has nothing to suppress
+		case p.peek().text == "." && p.peek().kind == tokOp:
+			p.next()
+			t := p.next()
+			if t.kind != tokIdent && t.kind != tokString {
+				return nil, fmt.Errorf("expected a key after '.' at position %d", t.pos+1)
+			}
+			n = fieldNode{n, t.text}
+		case p.accept("["):
+			if n, err = p.parseBracket(n); err != nil {
+				return nil, err
+			}
+		default:
+			return n, nil
+		}
+	}
+}
+
+// parseBracket parses the rest of [], [*] or [index] applied to from.
+func (p *parser) parseBracket(from node) (node, error) {
+	if p.accept("]") {
+		return iterateNode{from}, nil
+	}
+	if p.accept("*") {
+		return iterateNode{from}, p.expect("]")
+	}
+	idx, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	return indexNode{from, idx}, p.expect("]")
+}
+
+// parsePrimary parses a path start, literal, variable, function call,
+// parenthesised expression or list.
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	case tokString:
+		return literalNode{t.text}, nil
+	case tokNumber:
+		return literalNode{t.num}, nil
+	case tokVar:
+		if t.text == "" {
+			return rootNode{}, nil
+		}
+		return varNode{t.text}, nil
+	case tokIdent:
+		switch t.text {
+		case "true", "false":
+			return literalNode{t.text == "true"}, nil
+		case "null":
+			return literalNode{nil}, nil
+		}
+		if arities, ok := functions[t.text]; ok {
+			return p.parseCall(t, arities)
+		}
+		if next := p.peek(); next.text == "(" && next.kind == tokOp {
+			return nil, fmt.Errorf("%s is not a function at position %d", t.text, t.pos+1)
+		}
+		// A bare key is a path from the input, as in JSONPath
+		return fieldNode{identityNode{}, t.text}, nil
+	}
+
+	switch t.text {
+	case ".":
+		// .key, but not ". and": the key must follow the dot directly
+		if k := p.peek(); (k.kind == tokIdent || k.kind == tokString) && k.pos == t.pos+1 {
+			p.next()
+			return fieldNode{identityNode{}, k.text}, nil
+		}
+		return identityNode{}, nil
+	case "(":
+		n, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		return n, p.expect(")")
+	case "[":
+		if p.accept("]") {
+			return literalNode{[]interface{}{}}, nil
+		}
+		n, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		return collectNode{n}, p.expect("]")
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos+1)
+}
+
+// parseCall parses the arguments of a call to the function named by t,
+// which takes one of arities arguments separated by semicolons.
+func (p *parser) parseCall(t token, arities []int) (node, error) {
+	var args []node
+	if p.accept("(") {
+		for {
+			arg, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if !p.accept(";") {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+	}
+	for _, n := range arities {
+		if n == len(args) {
+			return callNode{t.text, args}, nil
+		}
+	}
+	return nil, fmt.Errorf("%s/%d is not defined at position %d", t.text, len(args), t.pos+1)
+}
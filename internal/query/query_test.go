@@ -0,0 +1,49 @@
+// Package query_test contains unit tests for the query package.
+package query
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParse_Errors tests the errors reported for invalid expressions.
+func TestParse_Errors(t *testing.T) {
+	tests := map[string]string{
+		"":                "unexpected end of expression",
+		".title ==":       "unexpected end of expression",
+		".tags[0":         `expected "]" at end of expression`,
+		`.title == "open`: "unterminated string at position 11",
+		"select(.a; .b)":  "select/2 is not defined",
+		"upper(.title)":   "upper is not a function",
+		".title )":        `unexpected ")" at position 8`,
+		".title = 1":      `unexpected '=' at position 8`,
+		".params. | .x":   "expected a key after '.'",
+		"$file | ][":      `unexpected "]" at position 9`,
+		"any(.a; .b; .c)": "any/3 is not defined",
+		"length(.tags)":   "length/1 is not defined",
+		"[.tags[] | test": "test/0 is not defined at position 12",
+		"[.tags[]":        `expected "]" at end of expression`,
+	}
+	for expr, want := range tests {
+		_, err := Parse(expr)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse(%q) error = %v; want %q", expr, err, want)
+		}
+	}
+}
+
+// TestParse_Forms tests that the jq and JSONPath forms of a path are equivalent.
+func TestParse_Forms(t *testing.T) {
+	doc := map[string]interface{}{"params": map[string]interface{}{"my key": "v"}, "tags": []interface{}{"a", "b"}}
+	for _, forms := range [][]string{
+		{".tags[1]", "tags[1]", "$.tags[1]", `.["tags"][-1]`, ".tags[]? | select(. == \"b\")"},
+		{`.params["my key"]`, `params."my key"`, `$.params.'my key'`},
+	} {
+		want := evalString(t, forms[0], doc)
+		for _, expr := range forms[1:] {
+			if got := evalString(t, expr, doc); got != want {
+				t.Errorf("%s = %s; want %s like %s", expr, got, want, forms[0])
+			}
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// Package internal_test contains unit tests for the internal package.
+package internal
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/vfs"
+)
+
+// TestRunQuery tests querying frontmatter and metadata across formats.
+func TestRunQuery(t *testing.T) {
+	fsys := vfs.NewMemFS(map[string][]byte{
+		"content/posts/a.md":       []byte("---\ntitle: A\nresources:\n- src: a.jpg\n  params:\n    credits: \"\"\n---\nBody\n"),
+		"content/posts/b/index.md": []byte("+++\ntitle = \"B\"\n[[resources]]\nsrc = \"b.jpg\"\n[resources.params]\ncredits = \"Ann\"\n+++\n"),
+		"content/about.md":         []byte("{\n\"title\": \"About\"\n}\n"),
+		"content/plain.md":         []byte("No frontmatter\n"),
+	})
+	cfg := config.Config{ContentDir: "content"}
+	tests := []struct {
+		expr string
+		opts QueryOptions
+		want string
+	}{
+		{"[.title, $section]", QueryOptions{}, "content/about.md: [\"About\",\"\"]\n" +
+			"content/posts/a.md: [\"A\",\"posts\"]\n" +
+			"content/posts/b/index.md: [\"B\",\"posts\"]\n"},
+		{"resources[].params.credits", QueryOptions{}, "content/posts/a.md: \"\"\n" +
+			"content/posts/b/index.md: \"Ann\"\n"},
+		{`any(resources[]; .params.credits == "")`, QueryOptions{Files: true}, "content/posts/a.md\n"},
+		{`$body | startswith("Body")`, QueryOptions{Files: true, Format: "json"}, "[\n  \"content/posts/a.md\"\n]\n"},
+		{".missing | select(. != null)", QueryOptions{Format: "json"}, "[]\n"},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		if err := RunQuery(cfg, fsys, tt.expr, tt.opts, &out); err != nil {
+			t.Fatalf("RunQuery(%q) error: %v", tt.expr, err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("RunQuery(%q):\ngot\n%s\nwant\n%s", tt.expr, out.String(), tt.want)
+		}
+	}
+
+	if err := RunQuery(cfg, fsys, ".title ==", QueryOptions{}, &bytes.Buffer{}); err == nil {
+		t.Errorf("expected an error for an invalid query")
+	}
+}
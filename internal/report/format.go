@@ -0,0 +1,335 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Formats are the report formats Write supports.
+var Formats = []string{"text", "json", "markdown", "html"}
+
+// Issue is a lint violation or other problem found in a file.
+type Issue struct {
+	File    string `json:"file"`
+	Message string `json:"message"`
+}
+
+// document is the report as written by the JSON, Markdown and HTML formats.
+type document struct {
+	Stats  Counts `json:"stats"`
+	DryRun bool   `json:"dryRun"`
+	// ElapsedSeconds is the duration of the run.
+	ElapsedSeconds float64      `json:"elapsedSeconds"`
+	Files          []FileAction `json:"files"`
+	Modified       []string     `json:"modified"`
+	Issues         []Issue      `json:"issues"`
+	Skipped        []FileAction `json:"skipped"`
+	Errors         []FileAction `json:"errors"`
+}
+
+// stat is a labelled count in the Markdown and HTML reports.
+type stat struct {
+	Label string
+	Value int
+}
+
+// Write writes the report for res to w in format: text, json, markdown or html.
+func Write(w io.Writer, res *Result, format string) error {
+	res.mu.Lock()
+	defer res.mu.Unlock()
+
+	switch format {
+	case "", "text":
+		writeText(w, res)
+		return nil
+	case "json":
+		out, err := json.MarshalIndent(newDocument(res), "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(out))
+		return err
+	case "markdown":
+		return writeMarkdown(w, newDocument(res))
+	case "html":
+		return htmlReport.Execute(w, newDocument(res))
+	}
+	return fmt.Errorf("unsupported report format %q: use %s", format, strings.Join(Formats, ", "))
+}
+
+// newDocument gathers the sections of the report for res.
+func newDocument(res *Result) document {
+	doc := document{
+		Stats:          res.Stats,
+		DryRun:         res.DryRun,
+		ElapsedSeconds: res.Elapsed.Seconds(),
+		Files:          append([]FileAction{}, res.Files...),
+		Modified:       append([]string{}, res.ModifiedFiles...),
+		Issues:         []Issue{},
+		Skipped:        []FileAction{},
+		Errors:         []FileAction{},
+	}
+	for _, f := range res.Files {
+		for _, issue := range f.Issues {
+			doc.Issues = append(doc.Issues, Issue{File: f.File, Message: issue})
+		}
+		switch f.Action {
+		case ActionSkipped:
+			doc.Skipped = append(doc.Skipped, f)
+		case ActionFailed:
+			doc.Errors = append(doc.Errors, f)
+		}
+	}
+	return doc
+}
+
+// Counts returns the labelled counts shown at the top of the report.
+func (d document) Counts() []stat {
+	return counts(d.Stats, d.DryRun)
+}
+
+// Operations returns the number of files each operation changed, by name.
+func (d document) Operations() []stat {
+	return operations(d.Stats)
+}
+
+// OperationsTitle heads the operations, which a dry run only plans.
+func (d document) OperationsTitle() string {
+	return operationsTitle(d.DryRun)
+}
+
+// Summary describes the mode and duration of the run.
+func (d document) Summary() string {
+	return summary(d.DryRun, time.Duration(d.ElapsedSeconds*float64(time.Second)))
+}
+
+// counts labels the file counts of stats. The lint counts are left out
+// when there are none.
+func counts(stats Counts, dryRun bool) []stat {
+	changed := "Changed"
+	if dryRun {
+		changed = "Changed (dry run, not written)"
+	}
+	labelled := []stat{
+		{"Processed", stats.Processed},
+		{"Matched condition", stats.Matched},
+		{changed, stats.Changed},
+		{"Written", stats.Written},
+		{"Declined at prompt", stats.Declined},
+		{"Unchanged", stats.Unchanged},
+		{"Not matching", stats.Skipped},
+		{"No frontmatter", stats.NoFrontmatter},
+		{"Parse errors", stats.ParseErrors},
+	}
+	if stats.LintFails > 0 || stats.LintFixed > 0 {
+		labelled = append(labelled, stat{"Lint violations", stats.LintFails}, stat{"Fields auto-fixed", stats.LintFixed})
+	}
+	return labelled
+}
+
+// operationsTitle heads the operations of a run, which are only planned in a
+// dry run.
+func operationsTitle(dryRun bool) string {
+	if dryRun {
+		return "Operations (dry run, planned)"
+	}
+	return "Operations"
+}
+
+// operations lists the operations of stats with the files each changed,
+// sorted by name.
+func operations(stats Counts) []stat {
+	ops := make([]stat, 0, len(stats.Operations))
+	for op, n := range stats.Operations {
+		ops = append(ops, stat{op, n})
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i].Label < ops[j].Label })
+	return ops
+}
+
+// summary describes a run as a dry run or not, with its duration.
+func summary(dryRun bool, elapsed time.Duration) string {
+	mode := "Run completed"
+	if dryRun {
+		mode = "Dry run (nothing written) completed"
+	}
+	return fmt.Sprintf("%s in %s", mode, elapsed.Round(time.Millisecond))
+}
+
+// writeText writes the console report. The caller holds res.mu.
+func writeText(w io.Writer, res *Result) {
+	stats := res.Stats
+	fmt.Fprintf(w, "\n📊 Report:\n")
+	for _, s := range counts(stats, res.DryRun) {
+		fmt.Fprintf(w, "%s: %d\n", s.Label, s.Value)
+	}
+	if ops := operations(stats); len(ops) > 0 {
+		fmt.Fprintf(w, "%s:\n", operationsTitle(res.DryRun))
+		for _, op := range ops {
+			fmt.Fprintf(w, "- %s: %s\n", op.Label, plural(op.Value, "file"))
+		}
+	}
+	fmt.Fprintf(w, "%s\n", summary(res.DryRun, res.Elapsed))
+
+	if len(res.ModifiedFiles) > 0 {
+		fmt.Fprintf(w, "\nModified Files:\n")
+		for _, file := range res.ModifiedFiles {
+			fmt.Fprintf(w, "- %s\n", file)
+		}
+	}
+}
+
+// writeMarkdown writes doc as a Markdown document.
+func writeMarkdown(w io.Writer, doc document) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Frontmatter report\n\n%s.\n\n| Count | Files |\n| --- | ---: |\n", doc.Summary())
+	for _, s := range doc.Counts() {
+		fmt.Fprintf(&b, "| %s | %d |\n", s.Label, s.Value)
+	}
+	if ops := doc.Operations(); len(ops) > 0 {
+		fmt.Fprintf(&b, "\n## %s\n\n| Operation | Files changed |\n| --- | ---: |\n", doc.OperationsTitle())
+		for _, op := range ops {
+			fmt.Fprintf(&b, "| %s | %d |\n", op.Label, op.Value)
+		}
+	}
+
+	b.WriteString("\n## Files\n\n")
+	if len(doc.Files) == 0 {
+		b.WriteString("No files processed.\n")
+	} else {
+		b.WriteString("| File | Action | Details |\n| --- | --- | --- |\n")
+		for _, f := range doc.Files {
+			details := f.Reason
+			if len(f.Issues) > 0 {
+				details = plural(len(f.Issues), "issue")
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", mdCell(f.File), f.Action, mdCell(details))
+		}
+	}
+
+	b.WriteString("\n## Lint violations and other issues\n\n")
+	if len(doc.Issues) == 0 {
+		b.WriteString("None.\n")
+	}
+	for _, issue := range doc.Issues {
+		fmt.Fprintf(&b, "- `%s`: %s\n", issue.File, issue.Message)
+	}
+
+	for _, section := range []struct {
+		title, column string
+		files         []FileAction
+	}{
+		{"Skipped files", "Reason", doc.Skipped},
+		{"Errors", "Error", doc.Errors},
+	} {
+		fmt.Fprintf(&b, "\n## %s\n\n", section.title)
+		if len(section.files) == 0 {
+			b.WriteString("None.\n")
+			continue
+		}
+		fmt.Fprintf(&b, "| File | %s |\n| --- | --- |\n", section.column)
+		for _, f := range section.files {
+			fmt.Fprintf(&b, "| %s | %s |\n", mdCell(f.Location()), mdCell(f.Reason))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mdCell escapes s for a Markdown table cell.
+func mdCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+// plural formats n with noun, adding an s unless n is 1.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// htmlReport renders a document as a self-contained HTML page.
+var htmlReport = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Frontmatter report</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem auto; max-width: 60rem; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1.5rem; }
+th, td { border: 1px solid #ccc; padding: .3rem .6rem; text-align: left; vertical-align: top; }
+th { background: #f3f3f3; }
+td.num { text-align: right; }
+.written { color: #1a7f37; } .failed { color: #cf222e; } .skipped, .unchanged { color: #6e7781; }
+</style>
+</head>
+<body>
+<h1>Frontmatter report</h1>
+<p>{{.Summary}}.</p>
+<table>
+{{- range .Counts}}
+<tr><th>{{.Label}}</th><td class="num">{{.Value}}</td></tr>
+{{- end}}
+</table>
+{{- with .Operations}}
+<h2>{{$.OperationsTitle}}</h2>
+<table>
+<tr><th>Operation</th><th>Files changed</th></tr>
+{{- range .}}
+<tr><td>{{.Label}}</td><td class="num">{{.Value}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+<h2>Files</h2>
+{{- if .Files}}
+<table>
+<tr><th>File</th><th>Action</th><th>Details</th></tr>
+{{- range .Files}}
+<tr><td><code>{{.File}}</code></td><td class="{{.Action}}">{{.Action}}</td><td>{{.Reason}}{{range .Issues}}<div>{{.}}</div>{{end}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No files processed.</p>
+{{- end}}
+<h2>Lint violations and other issues</h2>
+{{- if .Issues}}
+<ul>
+{{- range .Issues}}
+<li><code>{{.File}}</code>: {{.Message}}</li>
+{{- end}}
+</ul>
+{{- else}}
+<p>None.</p>
+{{- end}}
+<h2>Skipped files</h2>
+{{- if .Skipped}}
+<table>
+<tr><th>File</th><th>Reason</th></tr>
+{{- range .Skipped}}
+<tr><td><code>{{.File}}</code></td><td>{{.Reason}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>None.</p>
+{{- end}}
+<h2>Errors</h2>
+{{- if .Errors}}
+<table>
+<tr><th>File</th><th>Error</th></tr>
+{{- range .Errors}}
+<tr><td><code>{{.Location}}</code></td><td>{{.Reason}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>None.</p>
+{{- end}}
+</body>
+</html>
+`))
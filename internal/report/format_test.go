@@ -0,0 +1,101 @@
+// Package report_test contains unit tests for the report package.
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// result returns a run result with a file of each kind.
+func result() *Result {
+	return &Result{
+		Stats: Counts{
+			Processed: 4, Matched: 2, Changed: 1, Written: 1, Unchanged: 1, NoFrontmatter: 1, ParseErrors: 1,
+			LintFails: 1, Operations: map[string]int{"set": 1},
+		},
+		Elapsed:       1500 * time.Millisecond,
+		ModifiedFiles: []string{"a.md"},
+		Files: []FileAction{
+			{File: "a.md", Action: ActionWritten},
+			{File: "b.md", Action: ActionUnchanged, Issues: []string{"missing required field 'author'"}},
+			{File: "c.md", Action: ActionSkipped, Reason: "no frontmatter"},
+			{File: "d|e.md", Action: ActionFailed, Reason: "<bad> yaml"},
+		},
+	}
+}
+
+// TestWrite_JSON tests the sections of the JSON report.
+func TestWrite_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, result(), "json"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var doc document
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if doc.Stats.Processed != 4 || doc.Stats.Operations["set"] != 1 || doc.ElapsedSeconds != 1.5 || len(doc.Files) != 4 || len(doc.Modified) != 1 {
+		t.Errorf("unexpected report: %+v", doc)
+	}
+	if len(doc.Issues) != 1 || doc.Issues[0] != (Issue{File: "b.md", Message: "missing required field 'author'"}) {
+		t.Errorf("unexpected issues: %+v", doc.Issues)
+	}
+	if len(doc.Skipped) != 1 || doc.Skipped[0].File != "c.md" || len(doc.Errors) != 1 || doc.Errors[0].File != "d|e.md" {
+		t.Errorf("unexpected skipped files or errors: %+v %+v", doc.Skipped, doc.Errors)
+	}
+}
+
+// TestWrite_Documents tests the Markdown and HTML reports.
+func TestWrite_Documents(t *testing.T) {
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{"markdown", []string{
+			"Run completed in 1.5s.",
+			"| Processed | 4 |",
+			"| Parse errors | 1 |",
+			"| set | 1 |",
+			"| b.md | unchanged | 1 issue |",
+			"- `b.md`: missing required field 'author'",
+			"| c.md | no frontmatter |",
+			"| d\\|e.md | <bad> yaml |",
+		}},
+		{"html", []string{
+			"<!DOCTYPE html>",
+			"<tr><th>No frontmatter</th><td class=\"num\">1</td></tr>",
+			"<tr><td>set</td><td class=\"num\">1</td></tr>",
+			"<div>missing required field &#39;author&#39;</div>",
+			"<tr><td><code>d|e.md</code></td><td>&lt;bad&gt; yaml</td></tr>",
+		}},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := Write(&buf, result(), tt.format); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.format, err)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("%s report is missing %q:\n%s", tt.format, want, buf.String())
+			}
+		}
+	}
+
+	// A dry run only plans its operations
+	dry := result()
+	dry.DryRun = true
+	var buf bytes.Buffer
+	if err := Write(&buf, dry, "markdown"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "## Operations (dry run, planned)") {
+		t.Errorf("expected the operations to be labelled as planned:\n%s", buf.String())
+	}
+
+	if err := Write(&bytes.Buffer{}, result(), "pdf"); err == nil {
+		t.Errorf("expected an error for an unsupported format")
+	}
+}
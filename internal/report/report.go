@@ -1,36 +1,256 @@
 // Package report provides functionality for generating reports on the frontmatter processing.
 package report
 
-import "fmt"
-
-// Stats holds the statistics for the frontmatter processing.
-var Stats = struct {
-	Processed int
-	Matched   int
-	Updated   int
-	LintFails int
-	LintFixed int
-}{}
-
-// ModifiedFiles is a slice of strings containing the paths of the files that were modified.
-var ModifiedFiles []string
-
-// Print prints the report to the console.
-func Print() {
-	fmt.Printf("\n📊 Report:\n")
-	fmt.Printf("Processed: %d files\n", Stats.Processed)
-	fmt.Printf("Matched condition: %d\n", Stats.Matched)
-	fmt.Printf("Updated frontmatter: %d\n", Stats.Updated)
-	if Stats.LintFails > 0 || Stats.LintFixed > 0 {
-		fmt.Printf("Lint violations: %d\n", Stats.LintFails)
-		fmt.Printf("Fields auto-fixed: %d\n", Stats.LintFixed)
-	}
-	fmt.Printf("Skipped: %d\n", Stats.Processed-Stats.Matched)
-
-	if len(ModifiedFiles) > 0 {
-		fmt.Printf("\nModified Files:\n")
-		for _, file := range ModifiedFiles {
-			fmt.Printf("- %s\n", file)
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Counts holds the statistics for the frontmatter processing.
+type Counts struct {
+	// Processed is the number of content files read.
+	Processed int `json:"processed"`
+	// Matched is the number of files matching the condition.
+	Matched int `json:"matched"`
+	// Changed is the number of matched files whose frontmatter the run
+	// changed, whether or not the change was written.
+	Changed int `json:"changed"`
+	// Written is the number of changed files written.
+	Written int `json:"written"`
+	// Declined is the number of changes declined at the confirmation prompt.
+	Declined int `json:"declined"`
+	// Unchanged is the number of matched files that needed no change.
+	Unchanged int `json:"unchanged"`
+	// Skipped is the number of files with frontmatter not matching the condition.
+	Skipped int `json:"skipped"`
+	// NoFrontmatter is the number of files without a frontmatter block.
+	NoFrontmatter int `json:"noFrontmatter"`
+	// ParseErrors is the number of files whose frontmatter could not be parsed.
+	ParseErrors int `json:"parseErrors"`
+	LintFails   int `json:"lintFails"`
+	LintFixed   int `json:"lintFixed"`
+	// Operations maps each operation, such as "set" or "coerce", to the
+	// number of files in which it changed a value.
+	Operations map[string]int `json:"operations,omitempty"`
+}
+
+// Add adds the values of other to c.
+func (c *Counts) Add(other Counts) {
+	c.Processed += other.Processed
+	c.Matched += other.Matched
+	c.Changed += other.Changed
+	c.Written += other.Written
+	c.Declined += other.Declined
+	c.Unchanged += other.Unchanged
+	c.Skipped += other.Skipped
+	c.NoFrontmatter += other.NoFrontmatter
+	c.ParseErrors += other.ParseErrors
+	c.LintFails += other.LintFails
+	c.LintFixed += other.LintFixed
+	for op, n := range other.Operations {
+		c.AddOperation(op, n)
+	}
+}
+
+// AddOperation records that operation op changed n files.
+func (c *Counts) AddOperation(op string, n int) {
+	if n == 0 {
+		return
+	}
+	if c.Operations == nil {
+		c.Operations = map[string]int{}
+	}
+	c.Operations[op] += n
+}
+
+// Row holds the values --extract read from one file.
+type Row struct {
+	File string
+	// Values maps each extracted key set in the file to its value.
+	Values map[string]interface{}
+}
+
+// The actions a run takes on a file, as recorded in FileAction.
+const (
+	// ActionWritten means the updated frontmatter was written.
+	ActionWritten = "written"
+	// ActionDryRun means the file would have been written without --dry-run.
+	ActionDryRun = "dry-run"
+	// ActionDeclined means the change was declined at the confirmation prompt.
+	ActionDeclined = "declined"
+	// ActionUnchanged means the file matched but needed no change.
+	ActionUnchanged = "unchanged"
+	// ActionSkipped means the file was not processed, for the reason given.
+	ActionSkipped = "skipped"
+	// ActionFailed means the file could not be read, parsed or written.
+	ActionFailed = "failed"
+)
+
+// FileAction records what a run did with one file.
+type FileAction struct {
+	File   string `json:"file"`
+	Action string `json:"action"`
+	// Reason explains a skipped file, or holds the error of a failed one.
+	Reason string `json:"reason,omitempty"`
+	// Line is the line of the file a failure refers to, or 0 when unknown.
+	Line int `json:"line,omitempty"`
+	// Issues lists the lint violations and other problems found in the file.
+	Issues []string `json:"issues,omitempty"`
+	// Operations lists the operations, such as "set", that changed the
+	// file. They are counted in the statistics once the file is written, or
+	// as planned in a dry run.
+	Operations []string `json:"operations,omitempty"`
+}
+
+// Location returns the file, followed by the line of a failure if known.
+func (f FileAction) Location() string {
+	if f.Line > 0 {
+		return fmt.Sprintf("%s:%d", f.File, f.Line)
+	}
+	return f.File
+}
+
+// Result holds everything a single run produced. Each call to RunTool
+// returns a fresh Result, so repeated runs in one process never share state.
+// Its methods are safe for concurrent use.
+type Result struct {
+	mu sync.Mutex
+
+	// Stats holds the statistics for the run.
+	Stats Counts
+	// DryRun is set when the run showed changes without writing them.
+	DryRun bool
+	// Elapsed is the duration of the run.
+	Elapsed time.Duration
+	// ModifiedFiles contains the paths of the files that were written, in path order.
+	ModifiedFiles []string
+	// Files records the action taken on each file, in path order.
+	Files []FileAction
+	// Extracted contains the rows collected by --extract, in path order.
+	Extracted []Row
+	// Untranslated maps each language to the pages missing a translation in it.
+	Untranslated map[string][]string
+	// Status maps "draft", "future" and "expired" to the pages in that
+	// publishing state, in path order.
+	Status map[string][]string
+}
+
+// Record adds delta to the run statistics.
+func (r *Result) Record(delta Counts) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Stats.Add(delta)
+}
+
+// AddModified records path as modified.
+func (r *Result) AddModified(path string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ModifiedFiles = append(r.ModifiedFiles, path)
+}
+
+// AddFile records the action taken on a file and counts the changed,
+// written, declined and unchanged files, and the operations of the written
+// and dry-run ones. Files skipped or failing are counted when they are
+// read, through Record.
+func (r *Result) AddFile(action FileAction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Files = append(r.Files, action)
+	switch action.Action {
+	case ActionWritten:
+		r.Stats.Changed++
+		r.Stats.Written++
+		r.addOperations(action.Operations)
+	case ActionDryRun:
+		r.Stats.Changed++
+		r.addOperations(action.Operations)
+	case ActionDeclined:
+		r.Stats.Changed++
+		r.Stats.Declined++
+	case ActionUnchanged:
+		r.Stats.Unchanged++
+	}
+}
+
+// addOperations counts each of ops once. The caller holds r.mu.
+func (r *Result) addOperations(ops []string) {
+	for _, op := range ops {
+		r.Stats.AddOperation(op, 1)
+	}
+}
+
+// Failures returns the files recorded as failed, in path order.
+func (r *Result) Failures() []FileAction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var failed []FileAction
+	for _, f := range r.Files {
+		if f.Action == ActionFailed {
+			failed = append(failed, f)
+		}
+	}
+	return failed
+}
+
+// AddExtract records an --extract row.
+func (r *Result) AddExtract(row Row) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Extracted = append(r.Extracted, row)
+}
+
+// Print prints the report for res to the console.
+func Print(res *Result) {
+	res.mu.Lock()
+	defer res.mu.Unlock()
+	writeText(os.Stdout, res)
+}
+
+// PrintUntranslated prints the pages missing a translation, per language.
+func PrintUntranslated(res *Result) {
+	res.mu.Lock()
+	defer res.mu.Unlock()
+
+	var langs []string
+	for lang := range res.Untranslated {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+
+	fmt.Printf("\n🌐 Untranslated pages:\n")
+	if len(langs) == 0 {
+		fmt.Printf("All pages are translated.\n")
+	}
+	for _, lang := range langs {
+		fmt.Printf("\n%s (%d missing):\n", lang, len(res.Untranslated[lang]))
+		for _, page := range res.Untranslated[lang] {
+			fmt.Printf("- %s\n", page)
+		}
+	}
+}
+
+// statusLabels are the publishing states printed by PrintStatus, in order.
+var statusLabels = []struct{ key, label string }{
+	{"draft", "Drafts"},
+	{"future", "Scheduled for the future"},
+	{"expired", "Expired"},
+}
+
+// PrintStatus prints the pages that are drafts, scheduled or expired.
+func PrintStatus(res *Result) {
+	res.mu.Lock()
+	defer res.mu.Unlock()
+
+	fmt.Printf("\n🗓️  Publishing status:\n")
+	for _, s := range statusLabels {
+		pages := res.Status[s.key]
+		fmt.Printf("\n%s (%d):\n", s.label, len(pages))
+		for _, page := range pages {
+			fmt.Printf("- %s\n", page)
 		}
 	}
 }
@@ -2,19 +2,69 @@
 package report
 
 import (
+	"reflect"
+	"sync"
 	"testing"
 )
 
 // TestPrint tests the Print function.
 func TestPrint(t *testing.T) {
-	Stats = struct {
-		Processed int
-		Matched   int
-		Updated   int
-		LintFails int
-		LintFixed int
-	}{Processed: 10, Matched: 8, Updated: 5, LintFails: 2, LintFixed: 1}
-	ModifiedFiles = []string{"file1.md", "file2.md"}
-
-	Print()
+	res := &Result{
+		Stats:         Counts{Processed: 10, Matched: 8, Changed: 5, Written: 5, Unchanged: 3, Skipped: 2, LintFails: 2, LintFixed: 1},
+		ModifiedFiles: []string{"file1.md", "file2.md"},
+	}
+
+	Print(res)
+}
+
+// TestRecord_Concurrent tests that Record can be called from many goroutines.
+func TestRecord_Concurrent(t *testing.T) {
+	res := &Result{}
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res.Record(Counts{Processed: 1, Matched: 1})
+		}()
+	}
+	wg.Wait()
+
+	if res.Stats.Processed != 50 || res.Stats.Matched != 50 {
+		t.Errorf("expected 50 processed and matched, got %+v", res.Stats)
+	}
+}
+
+// TestPrintStatus tests the PrintStatus function.
+func TestPrintStatus(t *testing.T) {
+	res := &Result{Status: map[string][]string{"draft": {"a.md"}, "expired": {"b.md", "c.md"}}}
+
+	PrintStatus(res)
+}
+
+// TestAddFile tests counting the outcome of each file action.
+func TestAddFile(t *testing.T) {
+	res := &Result{}
+	for _, action := range []string{ActionWritten, ActionDryRun, ActionDeclined, ActionUnchanged, ActionSkipped, ActionFailed} {
+		res.AddFile(FileAction{File: action + ".md", Action: action})
+	}
+	want := Counts{Changed: 3, Written: 1, Declined: 1, Unchanged: 1}
+	if !reflect.DeepEqual(res.Stats, want) {
+		t.Errorf("Stats = %+v; want %+v", res.Stats, want)
+	}
+	if len(res.Files) != 6 {
+		t.Errorf("expected 6 file actions, got %d", len(res.Files))
+	}
+}
+
+// TestCounts_Add tests merging counts, including per-operation counts.
+func TestCounts_Add(t *testing.T) {
+	var c Counts
+	c.Add(Counts{Processed: 1, Operations: map[string]int{"set": 1}})
+	c.Add(Counts{Processed: 1, Operations: map[string]int{"set": 1, "coerce": 1}})
+	c.AddOperation("fix", 0)
+	want := Counts{Processed: 2, Operations: map[string]int{"set": 2, "coerce": 1}}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("Counts = %+v; want %+v", c, want)
+	}
 }
@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/bundle"
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/helpers"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/vfs"
+)
+
+// CheckResources lists the files of every leaf bundle matching cfg.Condition
+// and marks the images no resources entry covers. It reports resources
+// entries whose src matches no file, and featured_image and images values
+// naming files found neither in the page's bundle nor in a static directory.
+// It returns the issues found, each prefixed with the page path.
+func CheckResources(cfg config.Config, fsys vfs.FS) ([]string, error) {
+	paths, err := listContent(cfg, fsys)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := buildIndex(cfg, fsys, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []string
+	bundles := 0
+	for _, p := range paths {
+		front, ok := idx.fronts[p]
+		if !ok || front == nil {
+			continue
+		}
+		view := frontView(cfg, idx, p, front)
+		if cfg.Condition != "" && !helpers.EvaluateConditionsAt(view, cfg.Condition, now(cfg)) {
+			continue
+		}
+
+		var files []string
+		if bundle.IsLeafIndex(p) {
+			if files, err = bundleFiles(cfg, fsys, p); err != nil {
+				return issues, err
+			}
+			bundles++
+			resources := bundle.Parse(front["resources"])
+			unreferenced := map[string]bool{}
+			for _, name := range bundle.Unreferenced(resources, files) {
+				unreferenced[name] = true
+			}
+			fmt.Printf("📁 %s (%d files)\n", p, len(files))
+			for _, name := range files {
+				if unreferenced[name] {
+					fmt.Printf("   %s (no resources entry)\n", name)
+				} else {
+					fmt.Printf("   %s\n", name)
+				}
+			}
+			for _, src := range bundle.Unmatched(resources, files) {
+				issues = append(issues, fmt.Sprintf("%s: resources src %q matches no file in the bundle", p, src))
+			}
+		}
+
+		for _, ref := range bundle.ImageRefs(view) {
+			if !refExists(cfg, fsys, p, files, ref.Path) {
+				issues = append(issues, fmt.Sprintf("%s: %s %q is not in the bundle or a static directory", p, ref.Key, ref.Path))
+			}
+		}
+	}
+
+	fmt.Printf("\n🖼️ Resource check: %d bundles\n", bundles)
+	if len(issues) == 0 {
+		fmt.Printf("No resource problems found.\n")
+	}
+	for _, issue := range issues {
+		fmt.Printf("❌ %s\n", issue)
+	}
+	return issues, nil
+}
+
+// bundleFiles returns the resource files of the leaf bundle whose index file
+// is at p, relative to the bundle directory.
+func bundleFiles(cfg config.Config, fsys vfs.FS, p string) ([]string, error) {
+	return bundle.Files(fsys, path.Dir(p), func(name string) bool {
+		return helpers.IsContentFile(name, contentExtensions(cfg))
+	})
+}
+
+// refExists reports whether ref, referenced from the page at p with the
+// bundle files files, names a bundle file or a file in a static directory.
+// References starting with "/" are only looked up in the static directories.
+func refExists(cfg config.Config, fsys vfs.FS, p string, files []string, ref string) bool {
+	if i := strings.IndexAny(ref, "?#"); i >= 0 {
+		ref = ref[:i]
+	}
+	if !strings.HasPrefix(ref, "/") {
+		rel := path.Clean(ref)
+		if i := sort.SearchStrings(files, rel); i < len(files) && files[i] == rel {
+			return true
+		}
+	}
+	for _, dir := range siteOf(cfg).StaticDirs {
+		if _, err := fs.Stat(fsys, path.Join(dir, strings.TrimPrefix(ref, "/"))); err == nil {
+			return true
+		}
+	}
+	return false
+}
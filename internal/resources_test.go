@@ -0,0 +1,60 @@
+// Package internal_test contains unit tests for the internal package.
+package internal
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/vfs"
+)
+
+// resourcesSite returns a site with a bundle whose metadata has problems.
+func resourcesSite() *vfs.MemFS {
+	return vfs.NewMemFS(map[string][]byte{
+		"content/posts/a/index.md":      []byte("---\ntitle: A\nfeatured_image: cover.jpg\nimages: [/img/x.png, missing.png]\nresources:\n  - src: gallery/*.jpg\n    title: Gallery\n  - src: \"*.pdf\"\n---\nBody\n"),
+		"content/posts/a/cover.jpg":     []byte("jpg"),
+		"content/posts/a/gallery/1.jpg": []byte("jpg"),
+		"content/posts/b.md":            []byte("+++\nfeatured_image = \"/img/nope.png\"\ntitle = \"B\"\n+++\n"),
+		"static/img/x.png":              []byte("png"),
+	})
+}
+
+// TestCheckResources tests reporting unmatched resources and missing images.
+func TestCheckResources(t *testing.T) {
+	var issues []string
+	silenceStdout(t, func() {
+		var err error
+		issues, err = CheckResources(config.Config{ContentDir: "content"}, resourcesSite())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	want := []string{
+		`content/posts/a/index.md: resources src "*.pdf" matches no file in the bundle`,
+		`content/posts/a/index.md: images "missing.png" is not in the bundle or a static directory`,
+		`content/posts/b.md: featured_image "/img/nope.png" is not in the bundle or a static directory`,
+	}
+	if !reflect.DeepEqual(issues, want) {
+		t.Errorf("issues = %q; want %q", issues, want)
+	}
+}
+
+// TestRunToolFS_AddResources tests adding entries for unreferenced bundle images.
+func TestRunToolFS_AddResources(t *testing.T) {
+	fsys := resourcesSite()
+	cfg := config.Config{ContentDir: "content", AddResources: true, Yes: true}
+	res, err := captureRun(t, cfg, fsys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"content/posts/a/index.md"}; !reflect.DeepEqual(res.ModifiedFiles, want) {
+		t.Errorf("ModifiedFiles = %v; want %v", res.ModifiedFiles, want)
+	}
+	data, _ := fsys.ReadFile("content/posts/a/index.md")
+	want := "resources:\n- src: gallery/*.jpg\n  title: Gallery\n- src: '*.pdf'\n- src: cover.jpg\n"
+	if !strings.Contains(string(data), want) {
+		t.Errorf("expected a resources entry for cover.jpg, got %q", data)
+	}
+}
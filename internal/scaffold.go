@@ -0,0 +1,41 @@
+package internal
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/archetype"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/vfs"
+)
+
+// NewContent creates the content file target from the archetype of its
+// section, or of kind when given, as `hugo new` would. target is relative to
+// the content directory; a leading content directory is accepted too. With
+// cfg.DryRun the rendered file is printed instead of written.
+func NewContent(cfg config.Config, fsys vfs.FS, target, kind string, now time.Time) (string, error) {
+	rel := path.Clean(strings.ReplaceAll(target, "\\", "/"))
+	rel = strings.TrimPrefix(rel, path.Clean(cfg.ContentDir)+"/")
+	if path.Ext(rel) == "" {
+		return "", fmt.Errorf("%s has no file extension", target)
+	}
+
+	s := siteOf(cfg)
+	if cfg.DryRun {
+		out, err := archetype.Content(fsys, s, rel, kind, now)
+		if err != nil {
+			return "", err
+		}
+		fmt.Print(string(out))
+		return path.Join(cfg.ContentDir, rel), nil
+	}
+
+	created, err := archetype.Create(fsys, s, cfg.ContentDir, rel, kind, now)
+	if err != nil {
+		return "", err
+	}
+	fmt.Printf("✅ Created %s\n", created)
+	return created, nil
+}
@@ -0,0 +1,42 @@
+// Package internal_test contains unit tests for the internal package.
+package internal
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/vfs"
+)
+
+// TestNewContent tests creating content from a section archetype.
+func TestNewContent(t *testing.T) {
+	fsys := vfs.NewMemFS(map[string][]byte{
+		"archetypes/posts.md": []byte("---\ntitle: \"{{ replace .Name \"-\" \" \" | title }}\"\ndate: {{ .Date }}\n---\n"),
+	})
+	cfg := config.Config{ContentDir: "content"}
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, target := range []string{"posts/new-post.md", "content/posts/other-post.md"} {
+		var err error
+		silenceStdout(t, func() { _, err = NewContent(cfg, fsys, target, "", now) })
+		if err != nil {
+			t.Fatalf("NewContent(%q) error: %v", target, err)
+		}
+	}
+	data, err := fsys.ReadFile("content/posts/new-post.md")
+	if err != nil {
+		t.Fatalf("expected content to be created: %v", err)
+	}
+	if want := "---\ntitle: \"New Post\"\ndate: 2024-05-01T00:00:00Z\n---\n"; string(data) != want {
+		t.Errorf("got %q; want %q", data, want)
+	}
+	if _, err := fsys.ReadFile("content/posts/other-post.md"); err != nil {
+		t.Errorf("expected a leading content directory to be accepted: %v", err)
+	}
+
+	if _, err := NewContent(cfg, fsys, "posts/no-extension", "", now); err == nil || !strings.Contains(err.Error(), "extension") {
+		t.Errorf("expected an error for a path without extension, got %v", err)
+	}
+}
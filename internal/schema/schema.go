@@ -0,0 +1,295 @@
+// Package schema inventories the frontmatter keys used across a site and
+// infers a JSON Schema from them.
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/helpers"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// maxSamples is the number of distinct sample values kept per key.
+const maxSamples = 3
+
+// Field describes one key, or nested path, as seen across the files.
+type Field struct {
+	// Key is the path of the field: nested keys are joined with dots and
+	// the items of a list of maps are marked with [], e.g. "resources[].src".
+	Key string
+	// Files is the number of files using the key.
+	Files int
+	// Types maps each value type seen to the number of files using it.
+	Types map[string]int
+	// ItemTypes maps the type of each scalar list item to the number of
+	// files using it.
+	ItemTypes map[string]int
+	// Examples maps each value type to the first file using it.
+	Examples map[string]string
+	// Samples holds up to three distinct values, in the order first seen.
+	Samples []string
+}
+
+// Inconsistent reports whether the key holds values of more than one type.
+// Null values are not counted.
+func (f *Field) Inconsistent() bool {
+	n := len(f.Types)
+	if f.Types["null"] > 0 {
+		n--
+	}
+	return n > 1
+}
+
+// TypeNames returns the types seen for the key, the most used first.
+func (f *Field) TypeNames() []string {
+	return byCount(f.Types)
+}
+
+// Inventory accumulates the fields of the files added to it.
+type Inventory struct {
+	// Files is the number of files added.
+	Files  int
+	fields map[string]*Field
+}
+
+// New returns an empty Inventory.
+func New() *Inventory {
+	return &Inventory{fields: map[string]*Field{}}
+}
+
+// Add records the keys and values of front, the frontmatter of the file at path.
+func (inv *Inventory) Add(path string, front map[string]interface{}) {
+	inv.Files++
+	seen := map[string]bool{}
+	inv.walk(path, "", front, seen)
+}
+
+// walk records the fields of m, whose keys are prefixed with prefix.
+func (inv *Inventory) walk(path, prefix string, m map[string]interface{}, seen map[string]bool) {
+	for key, raw := range m {
+		v := helpers.NormalizeValue(raw)
+		full := prefix + key
+		f := inv.fields[full]
+		if f == nil {
+			f = &Field{Key: full, Types: map[string]int{}, ItemTypes: map[string]int{}, Examples: map[string]string{}}
+			inv.fields[full] = f
+		}
+		typ := TypeOf(v)
+		if !seen[full] {
+			f.Files++
+		}
+		if !seen[full+"\x00"+typ] {
+			f.Types[typ]++
+			if _, ok := f.Examples[typ]; !ok {
+				f.Examples[typ] = path
+			}
+		}
+		seen[full] = true
+		seen[full+"\x00"+typ] = true
+		f.addSample(v)
+
+		switch val := v.(type) {
+		case map[string]interface{}:
+			inv.walk(path, full+".", val, seen)
+		case []interface{}:
+			for _, item := range val {
+				if sub, ok := item.(map[string]interface{}); ok {
+					inv.walk(path, full+"[].", sub, seen)
+					continue
+				}
+				itemType := TypeOf(item)
+				if !seen[full+"[]\x00"+itemType] {
+					seen[full+"[]\x00"+itemType] = true
+					f.ItemTypes[itemType]++
+				}
+			}
+		}
+	}
+}
+
+// addSample keeps v as a sample value if it is new and there is room.
+func (f *Field) addSample(v interface{}) {
+	if len(f.Samples) >= maxSamples {
+		return
+	}
+	if _, isMap := v.(map[string]interface{}); isMap {
+		return
+	}
+	s := sampleText(v)
+	for _, existing := range f.Samples {
+		if existing == s {
+			return
+		}
+	}
+	f.Samples = append(f.Samples, s)
+}
+
+// Fields returns the fields seen, sorted by key.
+func (inv *Inventory) Fields() []*Field {
+	fields := make([]*Field, 0, len(inv.fields))
+	for _, f := range inv.fields {
+		fields = append(fields, f)
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Key < fields[j].Key })
+	return fields
+}
+
+// TypeOf returns the type name of a decoded frontmatter value: "string",
+// "bool", "int", "float", "date", "list", "map" or "null". Strings holding a
+// date, as YAML timestamps decode, are dates.
+func TypeOf(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "bool"
+	case int, int64, uint64:
+		return "int"
+	case float64:
+		return "float"
+	case time.Time, toml.LocalDate, toml.LocalDateTime:
+		return "date"
+	case []interface{}, []string:
+		return "list"
+	case map[string]interface{}, map[interface{}]interface{}:
+		return "map"
+	case string:
+		if _, ok := helpers.ParseDate(val); ok {
+			return "date"
+		}
+	}
+	return "string"
+}
+
+// Schema returns a JSON Schema describing the fields seen. Keys present in
+// every file are required at the top level.
+func (inv *Inventory) Schema() map[string]interface{} {
+	root := objectSchema()
+	var required []string
+	for _, f := range inv.Fields() {
+		node := root
+		segments := strings.Split(f.Key, ".")
+		for i, seg := range segments {
+			name, isList := strings.CutSuffix(seg, "[]")
+			props := node["properties"].(map[string]interface{})
+			child, ok := props[name].(map[string]interface{})
+			if !ok {
+				child = map[string]interface{}{}
+				props[name] = child
+			}
+			if i == len(segments)-1 {
+				describe(child, f)
+				break
+			}
+			if isList {
+				child["type"] = "array"
+				items, ok := child["items"].(map[string]interface{})
+				if !ok || items["properties"] == nil {
+					items = objectSchema()
+					child["items"] = items
+				}
+				node = items
+				continue
+			}
+			if child["properties"] == nil {
+				child["properties"] = map[string]interface{}{}
+			}
+			node = child
+		}
+		if !strings.ContainsAny(f.Key, ".[") && f.Files == inv.Files {
+			required = append(required, f.Key)
+		}
+	}
+	root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	if len(required) > 0 {
+		root["required"] = required
+	}
+	return root
+}
+
+// describe fills in the type of the schema node for field f.
+func describe(node map[string]interface{}, f *Field) {
+	node["type"] = jsonTypes(f.Types)
+	if f.Types["list"] > 0 && len(f.ItemTypes) > 0 {
+		if _, ok := node["items"]; !ok {
+			node["items"] = map[string]interface{}{"type": jsonTypes(f.ItemTypes)}
+		}
+	}
+	if f.Types["map"] > 0 && node["properties"] == nil {
+		node["properties"] = map[string]interface{}{}
+	}
+}
+
+// jsonTypes maps type names to JSON Schema types: a single name, or a
+// sorted list when there are several. Dates are strings, without a format,
+// as their layout varies between files.
+func jsonTypes(types map[string]int) interface{} {
+	names := map[string]string{
+		"string": "string", "date": "string", "bool": "boolean", "int": "integer",
+		"float": "number", "list": "array", "map": "object", "null": "null",
+	}
+	set := map[string]bool{}
+	for t := range types {
+		set[names[t]] = true
+	}
+	var out []string
+	for t := range set {
+		out = append(out, t)
+	}
+	sort.Strings(out)
+	if len(out) == 1 {
+		return out[0]
+	}
+	return out
+}
+
+// objectSchema returns an empty object schema.
+func objectSchema() map[string]interface{} {
+	return map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+}
+
+// byCount returns the keys of counts, largest count first, then by name.
+func byCount(counts map[string]int) []string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] != counts[names[j]] {
+			return counts[names[i]] > counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// sampleText formats a sample value, shortened to 40 characters.
+func sampleText(v interface{}) string {
+	var s string
+	switch val := v.(type) {
+	case []interface{}:
+		items := make([]string, len(val))
+		for i, item := range val {
+			items[i] = sampleText(item)
+		}
+		s = "[" + strings.Join(items, ", ") + "]"
+	case map[string]interface{}:
+		s = "{…}"
+	case time.Time:
+		s = val.Format(time.RFC3339)
+	case string:
+		s = fmt.Sprintf("%q", val)
+		if TypeOf(val) == "date" {
+			s = val
+		}
+	default:
+		s = fmt.Sprintf("%v", val)
+	}
+	if r := []rune(s); len(r) > 40 {
+		s = string(r[:39]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,69 @@
+// Package schema_test contains unit tests for the schema package.
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// inventory returns an inventory of three files with drifting fields.
+func inventory() *Inventory {
+	inv := New()
+	inv.Add("a.md", map[string]interface{}{
+		"title": "A", "date": "2024-01-01", "tags": []interface{}{"go", "web"},
+		"params": map[interface{}]interface{}{"author": "Ann"},
+	})
+	inv.Add("b.md", map[string]interface{}{
+		"title": "B", "date": time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), "tags": "solo",
+		"resources": []interface{}{map[string]interface{}{"src": "a.jpg"}, map[string]interface{}{"src": "b.jpg"}},
+	})
+	inv.Add("c.md", map[string]interface{}{"title": "C", "date": "2024-03-01", "weight": 2})
+	return inv
+}
+
+// TestInventory tests counting files, types and samples per key.
+func TestInventory(t *testing.T) {
+	fields := map[string]*Field{}
+	var keys []string
+	for _, f := range inventory().Fields() {
+		fields[f.Key] = f
+		keys = append(keys, f.Key)
+	}
+	if want := []string{"date", "params", "params.author", "resources", "resources[].src", "tags", "title", "weight"}; !reflect.DeepEqual(keys, want) {
+		t.Fatalf("keys = %v; want %v", keys, want)
+	}
+
+	date := fields["date"]
+	if date.Files != 3 || !reflect.DeepEqual(date.Types, map[string]int{"date": 3}) || date.Inconsistent() {
+		t.Errorf("unexpected date field: %+v", date)
+	}
+	src := fields["resources[].src"]
+	if src.Files != 1 || !reflect.DeepEqual(src.Samples, []string{`"a.jpg"`, `"b.jpg"`}) {
+		t.Errorf("unexpected resources[].src field: %+v", src)
+	}
+	tags := fields["tags"]
+	if !tags.Inconsistent() || !reflect.DeepEqual(tags.TypeNames(), []string{"list", "string"}) || tags.Examples["string"] != "b.md" {
+		t.Errorf("expected tags to be inconsistent, got %+v", tags)
+	}
+}
+
+// TestSchema tests inferring a JSON Schema with nested objects and arrays.
+func TestSchema(t *testing.T) {
+	out, err := json.Marshal(inventory().Schema())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"$schema":"https://json-schema.org/draft/2020-12/schema","properties":{` +
+		`"date":{"type":"string"},` +
+		`"params":{"properties":{"author":{"type":"string"}},"type":"object"},` +
+		`"resources":{"items":{"properties":{"src":{"type":"string"}},"type":"object"},"type":"array"},` +
+		`"tags":{"items":{"type":"string"},"type":["array","string"]},` +
+		`"title":{"type":"string"},` +
+		`"weight":{"type":"integer"}},` +
+		`"required":["date","title"],"type":"object"}`
+	if string(out) != want {
+		t.Errorf("Schema() =\n%s\nwant\n%s", out, want)
+	}
+}
@@ -0,0 +1,248 @@
+package internal
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/helpers"
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/report"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/vfs"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// RunExport writes cfg.ExtractKeys of the files matching cfg.Condition to w
+// as a spreadsheet in cfg.ExtractFormat, csv or tsv, keyed by a file column.
+// Lists are joined with ", " and missing fields are left empty, which is the
+// form RunImport reads back.
+func RunExport(cfg config.Config, fsys vfs.FS, w io.Writer) (*report.Result, error) {
+	res := &report.Result{}
+	paths, err := listContent(cfg, fsys)
+	if err != nil {
+		return res, err
+	}
+	var idx *siteIndex
+	if needsIndex(cfg) {
+		if idx, err = buildIndex(cfg, fsys, paths); err != nil {
+			return res, err
+		}
+	}
+	if err := processFiles(cfg, fsys, paths, idx, res); err != nil {
+		return res, err
+	}
+	return res, writeExtract(w, cfg, res)
+}
+
+// RunImport reads a spreadsheet written by RunExport, with comma as the
+// field separator, and applies the cells that differ from the files through
+// the normal diff, confirm and commit pipeline. Rows naming files that are
+// not in the content directories are reported and skipped.
+func RunImport(cfg config.Config, fsys vfs.FS, r io.Reader, comma rune) (*report.Result, error) {
+	rows, err := readSheet(r, comma)
+	if err != nil {
+		return &report.Result{}, err
+	}
+	paths, err := listContent(cfg, fsys)
+	if err != nil {
+		return &report.Result{}, err
+	}
+	known := map[string]bool{}
+	for _, p := range paths {
+		known[p] = true
+	}
+
+	cfg.Import = map[string]map[string]string{}
+	for _, row := range rows {
+		if !known[row.file] {
+			fmt.Printf("⚠️  Row %d: %s is not a content file, skipping\n", row.line, row.file)
+			continue
+		}
+		cfg.Import[row.file] = row.cells
+	}
+	return RunToolFS(cfg, fsys)
+}
+
+// sheetRow is a data row of an imported spreadsheet.
+type sheetRow struct {
+	line  int
+	file  string
+	cells map[string]string
+}
+
+// readSheet parses a spreadsheet whose first column is "file" and whose
+// other columns are frontmatter keys.
+func readSheet(r io.Reader, comma rune) ([]sheetRow, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = comma
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("import file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Spreadsheet applications often save a byte order mark
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	if strings.TrimSpace(header[0]) != "file" {
+		return nil, fmt.Errorf("import file must start with a %q column, got %q", "file", header[0])
+	}
+
+	var rows []sheetRow
+	seen := map[string]int{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		file := strings.TrimSpace(record[0])
+		if file == "" {
+			continue
+		}
+		if prev, ok := seen[file]; ok {
+			return nil, fmt.Errorf("%s appears on lines %d and %d", file, prev, line)
+		}
+		seen[file] = line
+		row := sheetRow{line: line, file: file, cells: map[string]string{}}
+		for i, key := range header[1:] {
+			row.cells[strings.TrimSpace(key)] = record[i+1]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// applyCells sets the fields of front whose cell differs from their value as
+// exported, converting each cell to the type of the value it replaces. An
+// emptied cell removes the field. It returns the number of fields changed
+// and a message for each cell that could not be converted.
+func applyCells(front map[string]interface{}, cells map[string]string) (int, []string) {
+	keys := make([]string, 0, len(cells))
+	for key := range cells {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	changed := 0
+	var issues []string
+	for _, key := range keys {
+		cell := strings.TrimSpace(cells[key])
+		old, exists := helpers.Lookup(front, key)
+		old = helpers.NormalizeValue(old)
+		switch {
+		case !exists && cell == "":
+			continue
+		case !exists:
+			helpers.SetPath(front, key, inferCell(cell))
+		case valueText(old) == cell:
+			continue
+		case cell == "":
+			helpers.DeletePath(front, key)
+		default:
+			v, err := coerceCell(old, cell)
+			if err != nil {
+				issues = append(issues, fmt.Sprintf("column '%s': %v", key, err))
+				continue
+			}
+			helpers.SetPath(front, key, v)
+		}
+		changed++
+	}
+	return changed, issues
+}
+
+// coerceCell converts cell to the type of old, the value it replaces.
+func coerceCell(old interface{}, cell string) (interface{}, error) {
+	invalid := func(kind string) error {
+		return fmt.Errorf("cannot convert %q to %s", cell, kind)
+	}
+	switch v := old.(type) {
+	case bool:
+		b, err := strconv.ParseBool(cell)
+		if err != nil {
+			return nil, invalid("a boolean")
+		}
+		return b, nil
+	case int:
+		n, err := strconv.Atoi(cell)
+		if err != nil {
+			return nil, invalid("an integer")
+		}
+		return n, nil
+	case int64:
+		n, err := strconv.ParseInt(cell, 10, 64)
+		if err != nil {
+			return nil, invalid("an integer")
+		}
+		return n, nil
+	case float64:
+		f, err := strconv.ParseFloat(cell, 64)
+		if err != nil {
+			return nil, invalid("a number")
+		}
+		return f, nil
+	case time.Time:
+		t, ok := helpers.ParseDateIn(cell, v.Location())
+		if !ok {
+			return nil, invalid("a date")
+		}
+		return t, nil
+	case toml.LocalDate, toml.LocalDateTime:
+		t, ok := helpers.ParseDate(cell)
+		if !ok {
+			return nil, invalid("a date")
+		}
+		if _, ok := v.(toml.LocalDate); ok {
+			return helpers.DateValue(t, "2006-01-02"), nil
+		}
+		return helpers.DateValue(t, "2006-01-02T15:04:05"), nil
+	case []interface{}:
+		items := []interface{}{}
+		for _, item := range strings.Split(cell, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			var value interface{} = item
+			if len(v) > 0 {
+				var err error
+				if value, err = coerceCell(v[0], item); err != nil {
+					return nil, err
+				}
+			}
+			items = append(items, value)
+		}
+		return items, nil
+	case map[string]interface{}:
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(cell), &m); err != nil {
+			return nil, invalid("a JSON object")
+		}
+		return m, nil
+	}
+	return cell, nil
+}
+
+// inferCell converts the cell of a field the file did not have: booleans
+// and numbers are typed, anything else is a string.
+func inferCell(cell string) interface{} {
+	if cell == "true" || cell == "false" {
+		return cell == "true"
+	}
+	if n, err := strconv.Atoi(cell); err == nil {
+		return n
+	}
+	if f, err := strconv.ParseFloat(cell, 64); err == nil {
+		return f
+	}
+	return cell
+}
@@ -0,0 +1,102 @@
+// Package internal_test contains unit tests for the internal package.
+package internal
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/vfs"
+)
+
+// sheetSite returns a site in YAML and TOML for the spreadsheet tests.
+func sheetSite() *vfs.MemFS {
+	return vfs.NewMemFS(map[string][]byte{
+		"content/a.md": []byte("---\ntitle: A\ndate: 2024-01-01\ndraft: true\ntags: [go, web]\nweight: 3\n---\nA\n"),
+		"content/b.md": []byte("+++\ndate = 2024-02-01T10:00:00Z\ntitle = \"B\"\nweight = 5\n+++\nB\n"),
+	})
+}
+
+// TestRunExport tests writing fields as TSV with empty cells for missing ones.
+func TestRunExport(t *testing.T) {
+	cfg := config.Config{ContentDir: "content", ExtractKeys: []string{"title", "date", "tags", "weight"}, ExtractFormat: "tsv"}
+	var out bytes.Buffer
+	if _, err := RunExport(cfg, sheetSite(), &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "file\ttitle\tdate\ttags\tweight\n" +
+		"content/a.md\tA\t2024-01-01\tgo, web\t3\n" +
+		"content/b.md\tB\t2024-02-01T10:00:00Z\t\t5\n"
+	if out.String() != want {
+		t.Errorf("got %q; want %q", out.String(), want)
+	}
+}
+
+// TestRunImport tests applying changed cells with type conversion.
+func TestRunImport(t *testing.T) {
+	fsys := sheetSite()
+	sheet := "\ufefffile,title,date,draft,tags,weight,params.author\n" +
+		"content/a.md,A,2024-01-01,false,\"go, web, hugo\",3,Ann\n" +
+		"content/b.md,B,2024-03-01T10:00:00Z,,,x5,\n" +
+		"content/missing.md,M,,,,,\n"
+	var err error
+	silenceStdout(t, func() {
+		_, err = RunImport(config.Config{ContentDir: "content", Yes: true}, fsys, strings.NewReader(sheet), ',')
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	a, _ := fsys.ReadFile("content/a.md")
+	for _, want := range []string{"draft: false\n", "tags: [go, web, hugo]\n", "params:\n  author: Ann\n", "date: 2024-01-01\n"} {
+		if !strings.Contains(string(a), want) {
+			t.Errorf("a.md: expected %q in %q", want, a)
+		}
+	}
+	b, _ := fsys.ReadFile("content/b.md")
+	if !strings.Contains(string(b), "date = 2024-03-01T10:00:00Z\n") || !strings.Contains(string(b), "weight = 5\n") {
+		t.Errorf("b.md: expected a new typed date and the unconvertible weight kept, got %q", b)
+	}
+}
+
+// TestApplyCells tests conversion to the type of the replaced value.
+func TestApplyCells(t *testing.T) {
+	front := map[string]interface{}{
+		"draft":   true,
+		"weight":  int64(1),
+		"rating":  4.5,
+		"date":    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		"aliases": []interface{}{"/old/"},
+		"remove":  "me",
+	}
+	cells := map[string]string{
+		"draft":   "false",
+		"weight":  "7",
+		"rating":  "high",
+		"date":    "2024-05-01",
+		"aliases": "/a/, /b/",
+		"remove":  "",
+		"new":     "12",
+	}
+	changed, issues := applyCells(front, cells)
+	if changed != 6 {
+		t.Errorf("changed = %d; want 6", changed)
+	}
+	if want := []string{`column 'rating': cannot convert "high" to a number`}; !reflect.DeepEqual(issues, want) {
+		t.Errorf("issues = %v; want %v", issues, want)
+	}
+	want := map[string]interface{}{
+		"draft":   false,
+		"weight":  int64(7),
+		"rating":  4.5,
+		"date":    time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		"aliases": []interface{}{"/a/", "/b/"},
+		"new":     12,
+	}
+	if !reflect.DeepEqual(front, want) {
+		t.Errorf("front = %v; want %v", front, want)
+	}
+}
@@ -0,0 +1,172 @@
+package internal
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/aggregate"
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/helpers"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/vfs"
+)
+
+// StatsOptions selects what RunStats computes over the pages matching
+// cfg.Condition. With neither GroupBy nor Distinct set it counts the pages.
+type StatsOptions struct {
+	// GroupBy counts the pages per value of this key. Each item of a list,
+	// such as tags, is a group of its own.
+	GroupBy string
+	// Distinct lists the values of this key.
+	Distinct string
+	// Bucket groups dates by "year" or "month".
+	Bucket string
+	// Sort orders groups by "count" or "value". It defaults to "count" for
+	// GroupBy without a Bucket and to "value" otherwise.
+	Sort  string
+	Limit int
+	// Format is "plain", "csv" or "json".
+	Format string
+}
+
+// RunStats writes the statistics selected by opts to w.
+func RunStats(cfg config.Config, fsys vfs.FS, opts StatsOptions, w io.Writer) error {
+	if opts.GroupBy != "" && opts.Distinct != "" {
+		return errors.New("use either --group-by or --distinct")
+	}
+	if opts.Bucket != "" && opts.Bucket != "year" && opts.Bucket != "month" {
+		return fmt.Errorf("invalid bucket %q: use year or month", opts.Bucket)
+	}
+	key := opts.GroupBy + opts.Distinct
+	if opts.Sort == "" {
+		opts.Sort = "value"
+		if opts.GroupBy != "" && opts.Bucket == "" {
+			opts.Sort = "count"
+		}
+	}
+	if opts.Sort != "count" && opts.Sort != "value" {
+		return fmt.Errorf("invalid sort %q: use count or value", opts.Sort)
+	}
+
+	_, views, err := matchingViews(cfg, fsys)
+	if err != nil {
+		return err
+	}
+	if key == "" {
+		return writeCount(w, opts.Format, len(views))
+	}
+
+	counter := aggregate.NewCounter(aggregate.Options{Bucket: opts.Bucket, Sort: opts.Sort, Limit: opts.Limit, Format: valueText})
+	for _, view := range views {
+		counter.Add(helpers.Lookup(view, key))
+	}
+	groups := counter.Groups()
+	if opts.Distinct != "" {
+		return writeDistinct(w, opts.Format, key, groups)
+	}
+	return writeGroups(w, opts.Format, key, groups)
+}
+
+// writeCount writes the number of matching pages.
+func writeCount(w io.Writer, format string, n int) error {
+	switch format {
+	case "", "plain":
+		fmt.Fprintln(w, n)
+	case "csv":
+		fmt.Fprintf(w, "count\n%d\n", n)
+	case "json":
+		fmt.Fprintf(w, "{\"count\": %d}\n", n)
+	default:
+		return unsupportedStatsFormat(format)
+	}
+	return nil
+}
+
+// writeGroups writes the size of each group, with pages lacking the key
+// shown as <missing>, an empty cell or null.
+func writeGroups(w io.Writer, format, key string, groups []aggregate.Group) error {
+	switch format {
+	case "", "plain":
+		width := 1
+		for _, g := range groups {
+			width = max(width, len(strconv.Itoa(g.Count)))
+		}
+		for _, g := range groups {
+			value := g.Value
+			if g.Missing {
+				value = missingValue
+			}
+			fmt.Fprintf(w, "%*d  %s\n", width, g.Count, value)
+		}
+	case "csv":
+		writer := csv.NewWriter(w)
+		_ = writer.Write([]string{key, "count"})
+		for _, g := range groups {
+			_ = writer.Write([]string{g.Value, strconv.Itoa(g.Count)})
+		}
+		writer.Flush()
+		return writer.Error()
+	case "json":
+		type row struct {
+			Value *string `json:"value"`
+			Count int     `json:"count"`
+		}
+		rows := make([]row, len(groups))
+		for i, g := range groups {
+			rows[i] = row{Count: g.Count}
+			if !g.Missing {
+				rows[i].Value = &groups[i].Value
+			}
+		}
+		out, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(out))
+	default:
+		return unsupportedStatsFormat(format)
+	}
+	return nil
+}
+
+// writeDistinct writes the distinct values of key. Pages lacking it are
+// not a value and are left out.
+func writeDistinct(w io.Writer, format, key string, groups []aggregate.Group) error {
+	values := []string{}
+	for _, g := range groups {
+		if !g.Missing {
+			values = append(values, g.Value)
+		}
+	}
+	switch format {
+	case "", "plain":
+		for _, v := range values {
+			fmt.Fprintln(w, v)
+		}
+	case "csv":
+		writer := csv.NewWriter(w)
+		_ = writer.Write([]string{key})
+		for _, v := range values {
+			_ = writer.Write([]string{v})
+		}
+		writer.Flush()
+		return writer.Error()
+	case "json":
+		out, err := json.MarshalIndent(values, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(out))
+	default:
+		return unsupportedStatsFormat(format)
+	}
+	return nil
+}
+
+// unsupportedStatsFormat returns the error for an unknown stats output format.
+func unsupportedStatsFormat(format string) error {
+	return fmt.Errorf("unsupported stats format %q: use plain, csv or json", format)
+}
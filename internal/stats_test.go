@@ -0,0 +1,53 @@
+// Package internal_test contains unit tests for the internal package.
+package internal
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/vfs"
+)
+
+// TestRunStats tests counts, groups and distinct values in each format.
+func TestRunStats(t *testing.T) {
+	fsys := vfs.NewMemFS(map[string][]byte{
+		"content/a.md": []byte("---\ntitle: A\ndate: 2023-05-01\nauthor: ann\ntags: [go, web]\n---\n"),
+		"content/b.md": []byte("---\ntitle: B\ndate: 2024-01-10\nauthor: bob\ntags: [go]\n---\n"),
+		"content/c.md": []byte("+++\ntitle = \"C\"\ndate = 2024-02-01T10:00:00Z\nauthor = \"ann\"\n+++\n"),
+		"content/d.md": []byte("---\ntitle: D\ndraft: true\nauthor: ann\n---\n"),
+	})
+	cfg := config.Config{ContentDir: "content"}
+
+	tests := []struct {
+		cond string
+		opts StatsOptions
+		want string
+	}{
+		{"", StatsOptions{}, "4\n"},
+		{"draft=true", StatsOptions{Format: "json"}, "{\"count\": 1}\n"},
+		{"", StatsOptions{GroupBy: "author"}, "3  ann\n1  bob\n"},
+		{"", StatsOptions{GroupBy: "tags", Format: "csv"}, "tags,count\ngo,2\nweb,1\n,2\n"},
+		{"", StatsOptions{GroupBy: "date", Bucket: "year"}, "1  2023\n2  2024\n1  <missing>\n"},
+		{"", StatsOptions{GroupBy: "author", Sort: "value", Limit: 1, Format: "json"},
+			"[\n  {\n    \"value\": \"ann\",\n    \"count\": 3\n  }\n]\n"},
+		{"", StatsOptions{Distinct: "author"}, "ann\nbob\n"},
+		{"author=ann", StatsOptions{Distinct: "tags", Format: "json"}, "[\n  \"go\",\n  \"web\"\n]\n"},
+	}
+	for _, tt := range tests {
+		cfg.Condition = tt.cond
+		var out bytes.Buffer
+		if err := RunStats(cfg, fsys, tt.opts, &out); err != nil {
+			t.Fatalf("%+v: unexpected error: %v", tt.opts, err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("%q %+v: got %q; want %q", tt.cond, tt.opts, out.String(), tt.want)
+		}
+	}
+
+	for _, bad := range []StatsOptions{{GroupBy: "a", Distinct: "b"}, {GroupBy: "date", Bucket: "week"}, {Sort: "size"}, {Format: "xml"}} {
+		if err := RunStats(cfg, fsys, bad, &bytes.Buffer{}); err == nil {
+			t.Errorf("%+v: expected an error", bad)
+		}
+	}
+}
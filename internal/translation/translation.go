@@ -0,0 +1,185 @@
+// Package translation groups the content files of a multilingual Hugo site
+// into translations of the same page.
+package translation
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/site"
+)
+
+// Page identifies a content file within its translation group.
+type Page struct {
+	Path string
+	Lang string
+	// Rel is the path relative to the content directory without its language
+	// suffix and extension, e.g. "posts/hello/index". Pages with the same Rel
+	// are translations of each other.
+	Rel string
+	// TranslationKey is the translationKey from the frontmatter, if any. It
+	// joins translations whose paths differ.
+	TranslationKey string
+}
+
+// Identify works out the language and translation key of the file at p.
+// contentDirs are the directories being processed; the language comes from a
+// filename suffix such as index.de.md, a per-language content directory, or
+// the site's default content language, in that order.
+func Identify(s *site.Site, contentDirs []string, p string, front map[string]interface{}) Page {
+	p = path.Clean(strings.ReplaceAll(p, "\\", "/"))
+	page := Page{Path: p, Lang: s.DefaultContentLanguage}
+
+	rel, root := p, ""
+	for _, dir := range contentDirs {
+		dir = path.Clean(strings.ReplaceAll(dir, "\\", "/"))
+		if strings.HasPrefix(p, dir+"/") && len(dir) > len(root) {
+			root, rel = dir, strings.TrimPrefix(p, dir+"/")
+		}
+	}
+	for code, lang := range s.Languages {
+		if path.Clean(lang.ContentDir) == root && lang.ContentDir != s.ContentDir {
+			page.Lang = code
+		}
+	}
+
+	base := strings.TrimSuffix(rel, path.Ext(rel))
+	if ext := path.Ext(base); ext != "" {
+		if _, ok := s.Languages[ext[1:]]; ok {
+			page.Lang = ext[1:]
+			base = strings.TrimSuffix(base, ext)
+		}
+	}
+	page.Rel = base
+
+	if tk, ok := front["translationKey"]; ok {
+		page.TranslationKey = fmt.Sprintf("%v", tk)
+	}
+	return page
+}
+
+// Index groups pages into translations: pages with the same path, and
+// pages with the same translationKey.
+type Index struct {
+	site *site.Site
+	// groups maps each group ID to its pages.
+	groups map[string][]Page
+	// ids maps the path and translationKey keys of the pages added to the
+	// ID of their group.
+	ids    map[string]string
+	byPath map[string]Page
+}
+
+// NewIndex returns an empty Index for the languages of s.
+func NewIndex(s *site.Site) *Index {
+	return &Index{site: s, groups: map[string][]Page{}, ids: map[string]string{}, byPath: map[string]Page{}}
+}
+
+// Add adds p to its translation group, merging the groups of its path and
+// its translationKey when they differ.
+func (ix *Index) Add(p Page) {
+	keys := []string{"path:" + p.Rel}
+	if p.TranslationKey != "" {
+		keys = append(keys, "translationKey:"+p.TranslationKey)
+	}
+	id := keys[0]
+	if existing, ok := ix.ids[id]; ok {
+		id = existing
+	}
+	for _, key := range keys {
+		if other, ok := ix.ids[key]; ok && other != id {
+			ix.groups[id] = append(ix.groups[id], ix.groups[other]...)
+			delete(ix.groups, other)
+			for k, v := range ix.ids {
+				if v == other {
+					ix.ids[k] = id
+				}
+			}
+		}
+		ix.ids[key] = id
+	}
+	ix.groups[id] = append(ix.groups[id], p)
+	ix.byPath[p.Path] = p
+}
+
+// Group returns the ID of the translation group of the page at p, or ""
+// if it is not indexed.
+func (ix *Index) Group(p string) string {
+	page, ok := ix.Page(p)
+	if !ok {
+		return ""
+	}
+	return ix.ids["path:"+page.Rel]
+}
+
+// Page returns the indexed page at p.
+func (ix *Index) Page(p string) (Page, bool) {
+	page, ok := ix.byPath[path.Clean(strings.ReplaceAll(p, "\\", "/"))]
+	return page, ok
+}
+
+// Translations returns every page in the same group as the page at p,
+// including itself, ordered by language weight.
+func (ix *Index) Translations(p string) []Page {
+	page, ok := ix.Page(p)
+	if !ok {
+		return nil
+	}
+	group := append([]Page(nil), ix.groups[ix.Group(page.Path)]...)
+	order := map[string]int{}
+	for i, code := range ix.site.LanguageCodes() {
+		order[code] = i
+	}
+	sort.SliceStable(group, func(i, j int) bool {
+		oi, iok := order[group[i].Lang]
+		oj, jok := order[group[j].Lang]
+		if iok != jok {
+			return iok
+		}
+		if oi != oj {
+			return oi < oj
+		}
+		return group[i].Path < group[j].Path
+	})
+	return group
+}
+
+// Reference returns the translation other pages in the group are compared
+// against: the one in the default content language, or else the first by
+// language weight.
+func (ix *Index) Reference(p string) (Page, bool) {
+	group := ix.Translations(p)
+	if len(group) == 0 {
+		return Page{}, false
+	}
+	for _, page := range group {
+		if page.Lang == ix.site.DefaultContentLanguage {
+			return page, true
+		}
+	}
+	return group[0], true
+}
+
+// Untranslated returns, for each configured language, the reference paths of
+// the pages that have no translation in that language, sorted.
+func (ix *Index) Untranslated() map[string][]string {
+	missing := map[string][]string{}
+	for _, group := range ix.groups {
+		have := map[string]bool{}
+		for _, page := range group {
+			have[page.Lang] = true
+		}
+		ref, _ := ix.Reference(group[0].Path)
+		for _, code := range ix.site.LanguageCodes() {
+			if !have[code] {
+				missing[code] = append(missing[code], ref.Path)
+			}
+		}
+	}
+	for code := range missing {
+		sort.Strings(missing[code])
+	}
+	return missing
+}
@@ -0,0 +1,113 @@
+// Package translation_test contains unit tests for the translation package.
+package translation
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/site"
+)
+
+// multilingual returns a site with English, German and French content.
+func multilingual() *site.Site {
+	return site.FromMap(map[string]interface{}{
+		"defaultContentLanguage": "en",
+		"languages": map[string]interface{}{
+			"en": map[string]interface{}{"weight": 1},
+			"de": map[string]interface{}{"weight": 2},
+			"fr": map[string]interface{}{"weight": 3, "contentDir": "content/fr"},
+		},
+	})
+}
+
+// TestIdentify tests detecting the language and key of content files.
+func TestIdentify(t *testing.T) {
+	s := multilingual()
+	dirs := []string{"content", "content/fr"}
+
+	tests := []struct {
+		path string
+		want Page
+	}{
+		{"content/posts/a/index.md", Page{"content/posts/a/index.md", "en", "posts/a/index", ""}},
+		{"content/posts/a/index.de.md", Page{"content/posts/a/index.de.md", "de", "posts/a/index", ""}},
+		{"content/fr/posts/a/index.md", Page{"content/fr/posts/a/index.md", "fr", "posts/a/index", ""}},
+		{"content/posts/v1.2.md", Page{"content/posts/v1.2.md", "en", "posts/v1.2", ""}},
+	}
+	for _, tt := range tests {
+		if got := Identify(s, dirs, tt.path, nil); got != tt.want {
+			t.Errorf("Identify(%q) = %+v; want %+v", tt.path, got, tt.want)
+		}
+	}
+
+	tk := Identify(s, dirs, "content/posts/hallo.de.md", map[string]interface{}{"translationKey": "hello"})
+	if tk.Rel != "posts/hallo" || tk.TranslationKey != "hello" {
+		t.Errorf("expected the path and translationKey to be kept, got %+v", tk)
+	}
+}
+
+// TestIndex tests grouping translations and reporting missing ones.
+func TestIndex(t *testing.T) {
+	s := multilingual()
+	dirs := []string{"content", "content/fr"}
+	ix := NewIndex(s)
+	for _, p := range []string{"content/a.de.md", "content/a.md", "content/fr/a.md", "content/b.md"} {
+		ix.Add(Identify(s, dirs, p, nil))
+	}
+
+	var langs []string
+	for _, page := range ix.Translations("content/fr/a.md") {
+		langs = append(langs, page.Lang)
+	}
+	if !reflect.DeepEqual(langs, []string{"en", "de", "fr"}) {
+		t.Errorf("unexpected translation order: %v", langs)
+	}
+	if ref, _ := ix.Reference("content/a.de.md"); ref.Path != "content/a.md" {
+		t.Errorf("expected English reference, got %v", ref)
+	}
+
+	want := map[string][]string{"de": {"content/b.md"}, "fr": {"content/b.md"}}
+	if got := ix.Untranslated(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Untranslated() = %v; want %v", got, want)
+	}
+}
+
+// TestIndex_TranslationKey tests grouping pages by path and joining them by translationKey.
+func TestIndex_TranslationKey(t *testing.T) {
+	s := multilingual()
+	dirs := []string{"content", "content/fr"}
+	ix := NewIndex(s)
+	pages := []struct {
+		path string
+		key  string
+	}{
+		{"content/hello/index.en.md", "hello"},
+		// Same path without a key: still a translation of the English page
+		{"content/hello/index.de.md", ""},
+		// Different path joined by the key
+		{"content/fr/bonjour/index.md", "hello"},
+		{"content/other.md", ""},
+	}
+	for _, p := range pages {
+		front := map[string]interface{}{}
+		if p.key != "" {
+			front["translationKey"] = p.key
+		}
+		ix.Add(Identify(s, dirs, p.path, front))
+	}
+
+	var paths []string
+	for _, page := range ix.Translations("content/hello/index.de.md") {
+		paths = append(paths, page.Path)
+	}
+	if want := []string{"content/hello/index.en.md", "content/hello/index.de.md", "content/fr/bonjour/index.md"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("Translations() = %v; want %v", paths, want)
+	}
+	if ix.Group("content/fr/bonjour/index.md") != ix.Group("content/hello/index.de.md") {
+		t.Errorf("expected one group for the three translations")
+	}
+	want := map[string][]string{"de": {"content/other.md"}, "fr": {"content/other.md"}}
+	if got := ix.Untranslated(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Untranslated() = %v; want %v", got, want)
+	}
+}
@@ -0,0 +1,77 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/permalink"
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/translation"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/vfs"
+)
+
+// CheckURLs computes the URL and aliases of every page in the content
+// directories and prints the collisions found: pages sharing a URL, aliases
+// declared twice, aliases shadowing a page and aliases of unpublished pages.
+// It returns the issues found.
+func CheckURLs(cfg config.Config, fsys vfs.FS) ([]permalink.Issue, error) {
+	paths, err := listContent(cfg, fsys)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := buildIndex(cfg, fsys, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]permalink.Page, 0, len(idx.fronts))
+	for path, front := range idx.fronts {
+		pages = append(pages, pageOf(cfg, idx, path, front))
+	}
+	sort.Slice(pages, func(i, j int) bool { return pages[i].Path < pages[j].Path })
+
+	issues := permalink.Check(siteOf(cfg), pages)
+	fmt.Printf("\n🔗 URL check: %d pages\n", len(pages))
+	if len(issues) == 0 {
+		fmt.Printf("No URL collisions found.\n")
+	}
+	for _, issue := range issues {
+		fmt.Printf("❌ %s\n", issue)
+	}
+	return issues, nil
+}
+
+// pageOf describes the page at path for URL computation, using the
+// frontmatter as Hugo sees it. Without an index the page is identified from
+// its path alone.
+func pageOf(cfg config.Config, idx *siteIndex, path string, front map[string]interface{}) permalink.Page {
+	var page translation.Page
+	if idx != nil {
+		page, _ = idx.translations.Page(path)
+	} else {
+		page = translation.Identify(siteOf(cfg), append([]string{cfg.ContentDir}, cfg.ContentDirs...), path, front)
+	}
+	return permalink.Page{Path: path, Rel: page.Rel, Lang: page.Lang, Front: frontView(cfg, idx, path, front)}
+}
+
+// addAlias appends oldURL to the aliases of front when the page moved from
+// it to newURL, unless it is already an alias. It reports whether it did.
+func addAlias(front map[string]interface{}, oldURL, newURL string) bool {
+	if oldURL == newURL {
+		return false
+	}
+	var aliases []interface{}
+	switch v := front["aliases"].(type) {
+	case []interface{}:
+		aliases = v
+	case string:
+		aliases = []interface{}{v}
+	}
+	for _, alias := range aliases {
+		if permalink.Normalize(fmt.Sprintf("%v", alias)) == oldURL {
+			return false
+		}
+	}
+	front["aliases"] = append(aliases, oldURL)
+	return true
+}
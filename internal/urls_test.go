@@ -0,0 +1,76 @@
+// Package internal_test contains unit tests for the internal package.
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/site"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/vfs"
+)
+
+// TestCheckURLs tests finding collisions across a site with permalink patterns.
+func TestCheckURLs(t *testing.T) {
+	fsys := vfs.NewMemFS(map[string][]byte{
+		"content/posts/a.md": []byte("---\ntitle: Hello\ndate: 2024-03-05\n---\n"),
+		"content/posts/b.md": []byte("---\ntitle: Hello\ndate: 2024-03-20\n---\n"),
+		"content/posts/c.md": []byte("---\ntitle: Other\ndate: 2024-03-20\naliases: [/old/]\n---\n"),
+		"content/old.md":     []byte("---\ntitle: Old\n---\n"),
+	})
+	cfg := config.Config{
+		ContentDir: "content",
+		Site:       site.FromMap(map[string]interface{}{"permalinks": map[string]interface{}{"posts": "/:year/:slug/"}}),
+	}
+
+	var issues []string
+	silenceStdout(t, func() {
+		found, err := CheckURLs(cfg, fsys)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, issue := range found {
+			issues = append(issues, issue.Kind+" "+issue.URL)
+		}
+	})
+	want := "[duplicate /2024/hello/ alias-shadows-page /old/]"
+	if got := fmt.Sprint(issues); got != want {
+		t.Errorf("issues = %s; want %s", got, want)
+	}
+}
+
+// TestAddAlias tests appending an old URL to aliases without duplicates.
+func TestAddAlias(t *testing.T) {
+	front := map[string]interface{}{"aliases": "/first/"}
+	if !addAlias(front, "/old/", "/new/") {
+		t.Fatal("expected an alias to be added")
+	}
+	if addAlias(front, "/old/", "/new/") || addAlias(front, "/first/", "/new/") || addAlias(front, "/new/", "/new/") {
+		t.Error("expected existing aliases and unchanged URLs to be skipped")
+	}
+	if got := fmt.Sprint(front["aliases"]); got != "[/first/ /old/]" {
+		t.Errorf("aliases = %s; want [/first/ /old/]", got)
+	}
+}
+
+// TestRunToolFS_AddAliases tests keeping the old URL when --set changes a slug.
+func TestRunToolFS_AddAliases(t *testing.T) {
+	fsys := vfs.NewMemFS(map[string][]byte{
+		"content/posts/a.md": []byte("---\ntitle: A\nslug: first\n---\n"),
+		"content/posts/b.md": []byte("---\ntitle: B\nslug: second\naliases:\n- /posts/second/\n---\n"),
+	})
+	cfg := config.Config{ContentDir: "content", SetField: "slug=renamed", AddAliases: true, Yes: true}
+	if _, err := captureRun(t, cfg, fsys); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	a, _ := fsys.ReadFile("content/posts/a.md")
+	if want := "---\ntitle: A\naliases: [/posts/first/]\nslug: renamed\n---\n"; string(a) != want {
+		t.Errorf("got %q; want %q", a, want)
+	}
+	b, _ := fsys.ReadFile("content/posts/b.md")
+	if strings.Count(string(b), "/posts/second/") != 1 {
+		t.Errorf("expected the existing alias not to be duplicated, got %q", b)
+	}
+}
@@ -1,6 +1,13 @@
 // Package config defines the configuration options for the hugo-frontmatter-toolbox.
 package config
 
+import (
+	"time"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/site"
+)
+
+// Config holds the options for a single run.
 type Config struct {
 	ContentDir       string
 	SetField         string
@@ -15,6 +22,54 @@ type Config struct {
 	GitCommit        bool
 	GcMsg            string
 	Yes              bool
-	ExtractKey       string
+	ExtractKeys      []string
 	ExtractFormat    string
+	Jobs             int
+	Source           string
+	SharedFields     []string
+	AllTranslations  bool
+	Untranslated     bool
+	Effective        bool
+	LintArchetypes   bool
+	AddAliases       bool
+	AddResources     bool
+	// ReportFormat is the format of the --report output: text, json,
+	// markdown or html. Empty means text.
+	ReportFormat string
+	// ReportFile is the path the report is written to; empty means stdout.
+	ReportFile string
+	// KeepGoing records the files that cannot be read, parsed or written
+	// and processes the rest, instead of stopping at the first.
+	KeepGoing bool
+	// ContentDirs lists further content directories, such as per-language
+	// ones, that are processed after ContentDir.
+	ContentDirs []string
+	// Site is the Hugo site configuration, or nil when none was found.
+	Site *site.Site
+	// Lifecycle is the publishing operation applied to matching pages:
+	// "publish", "schedule" or "expire".
+	Lifecycle string
+	// ScheduleAt is the publish date set by the schedule operation.
+	ScheduleAt time.Time
+	// OlderThan restricts the expire operation to pages dated before Now
+	// shifted by this negative offset, e.g. "-2y".
+	OlderThan string
+	// DateLayout is the Go time layout the normalize-dates operation rewrites
+	// date fields into; empty disables the operation.
+	DateLayout string
+	// DateLocation is the time zone dates are rewritten into. Dates without
+	// a zone are read as wall-clock time in it. nil means UTC.
+	DateLocation *time.Location
+	// DateKeys are the fields normalize-dates rewrites; empty means every
+	// key of the site's frontmatter date mappings.
+	DateKeys []string
+	// Coerce maps frontmatter keys, or dotted paths, to the type the coerce
+	// operation converts their values to: list, bool, int, float, date or string.
+	Coerce map[string]string
+	// Import maps content file paths to the cells of an imported spreadsheet
+	// row, by frontmatter key. Cells that differ from the file are applied.
+	Import map[string]map[string]string
+	// Now is the reference time for relative dates in conditions, lifecycle
+	// operations and status; the zero value means the current time.
+	Now time.Time
 }
@@ -0,0 +1,76 @@
+// Package frontmatter is the public Go API of the hugo-frontmatter-toolbox.
+// It lets other tools parse, query and edit Hugo content files without
+// shelling out to the command line binary.
+package frontmatter
+
+import (
+	"errors"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/helpers"
+)
+
+// Format identifies the frontmatter syntax by its opening delimiter.
+type Format string
+
+// Supported frontmatter formats.
+const (
+	YAML Format = helpers.YamlDelimiter
+	TOML Format = helpers.TomlDelimiter
+	JSON Format = helpers.JsonDelimiter
+)
+
+// ErrNoFrontmatter is returned by Parse when a document has no frontmatter block.
+var ErrNoFrontmatter = errors.New("document has no frontmatter")
+
+// Document is a Hugo content file split into its frontmatter fields and body.
+type Document struct {
+	Format Format
+	Fields map[string]interface{}
+	Body   []byte
+}
+
+// Parse splits data into frontmatter and body and decodes the frontmatter.
+func Parse(data []byte) (*Document, error) {
+	delimiter, fm, body := helpers.SplitFrontmatter(data)
+	if delimiter == "" {
+		return nil, ErrNoFrontmatter
+	}
+	fields, err := helpers.UnmarshalFrontmatter(delimiter, fm)
+	if err != nil {
+		return nil, err
+	}
+	return &Document{Format: Format(delimiter), Fields: fields, Body: body}, nil
+}
+
+// Get returns the value of key and whether it is present.
+func (d *Document) Get(key string) (interface{}, bool) {
+	v, ok := d.Fields[key]
+	return v, ok
+}
+
+// Set sets key to value.
+func (d *Document) Set(key string, value interface{}) {
+	if d.Fields == nil {
+		d.Fields = make(map[string]interface{})
+	}
+	d.Fields[key] = value
+}
+
+// Delete removes key from the frontmatter.
+func (d *Document) Delete(key string) {
+	delete(d.Fields, key)
+}
+
+// Frontmatter encodes the fields in the document's format, without delimiters.
+func (d *Document) Frontmatter() ([]byte, error) {
+	return helpers.MarshalFrontmatter(string(d.Format), d.Fields)
+}
+
+// Bytes serializes the full document, frontmatter and body.
+func (d *Document) Bytes() ([]byte, error) {
+	fm, err := d.Frontmatter()
+	if err != nil {
+		return nil, err
+	}
+	return helpers.JoinFrontmatter(string(d.Format), fm, d.Body), nil
+}
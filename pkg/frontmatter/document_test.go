@@ -0,0 +1,62 @@
+// Package frontmatter_test contains unit tests for the public frontmatter API.
+package frontmatter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestParse tests parsing documents in each supported format.
+func TestParse(t *testing.T) {
+	cases := []struct {
+		input  string
+		format Format
+	}{
+		{"---\ntitle: Test\n---\nBody\n", YAML},
+		{"+++\ntitle = 'Test'\n+++\nBody\n", TOML},
+		{"{\n \"title\": \"Test\"\n}\nBody\n", JSON},
+	}
+
+	for _, c := range cases {
+		doc, err := Parse([]byte(c.input))
+		if err != nil {
+			t.Fatalf("Parse(%q) error: %v", c.input, err)
+		}
+		if doc.Format != c.format {
+			t.Errorf("expected format %q, got %q", c.format, doc.Format)
+		}
+		if v, _ := doc.Get("title"); v != "Test" {
+			t.Errorf("expected title Test, got %v", v)
+		}
+	}
+}
+
+// TestParse_NoFrontmatter tests that Parse reports documents without frontmatter.
+func TestParse_NoFrontmatter(t *testing.T) {
+	if _, err := Parse([]byte("Just a body")); !errors.Is(err, ErrNoFrontmatter) {
+		t.Errorf("expected ErrNoFrontmatter, got %v", err)
+	}
+}
+
+// TestDocument_Edit tests editing and serializing a document.
+func TestDocument_Edit(t *testing.T) {
+	doc, err := Parse([]byte("---\ntitle: Test\nobsolete: yes\n---\nBody\n"))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	doc.Set("draft", false)
+	doc.Delete("obsolete")
+
+	out, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes error: %v", err)
+	}
+	want := "---\ntitle: Test\ndraft: false\n---\nBody\n"
+	if string(out) != want {
+		t.Errorf("Bytes() = %q; want %q", out, want)
+	}
+	if strings.Contains(string(out), "obsolete") {
+		t.Errorf("expected obsolete to be removed")
+	}
+}
@@ -0,0 +1,48 @@
+package frontmatter
+
+import (
+	"time"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal/helpers"
+)
+
+// Query is a condition in the syntax accepted by the --if flag,
+// e.g. "date<2023-01-01 AND tags contains 'go'".
+type Query struct {
+	expr string
+}
+
+// NewQuery returns a Query for expr. An empty expression matches every document.
+func NewQuery(expr string) Query {
+	return Query{expr: expr}
+}
+
+// String returns the query expression.
+func (q Query) String() string {
+	return q.expr
+}
+
+// Match reports whether the document satisfies the query, evaluating
+// relative dates such as "now" and "-90d" against the current time.
+func (q Query) Match(d *Document) bool {
+	return q.MatchFields(d.Fields)
+}
+
+// MatchAt is like Match but evaluates relative dates against now.
+func (q Query) MatchAt(d *Document, now time.Time) bool {
+	return q.MatchFieldsAt(d.Fields, now)
+}
+
+// MatchFields reports whether the frontmatter fields satisfy the query,
+// evaluating relative dates against the current time.
+func (q Query) MatchFields(fields map[string]interface{}) bool {
+	return q.MatchFieldsAt(fields, time.Now())
+}
+
+// MatchFieldsAt is like MatchFields but evaluates relative dates against now.
+func (q Query) MatchFieldsAt(fields map[string]interface{}, now time.Time) bool {
+	if q.expr == "" {
+		return true
+	}
+	return helpers.EvaluateConditionsAt(fields, q.expr, now)
+}
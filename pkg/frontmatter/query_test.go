@@ -0,0 +1,45 @@
+// Package frontmatter_test contains unit tests for the public frontmatter API.
+package frontmatter
+
+import (
+	"testing"
+	"time"
+)
+
+// TestQuery_Match tests matching documents against queries.
+func TestQuery_Match(t *testing.T) {
+	doc := &Document{Format: YAML, Fields: map[string]interface{}{
+		"draft": false,
+		"tags":  []interface{}{"go", "hugo"},
+	}}
+
+	tests := []struct {
+		expr  string
+		match bool
+	}{
+		{"", true},
+		{"draft=false", true},
+		{"draft=true", false},
+		{"tags contains 'go' AND draft=false", true},
+		{"tags contains 'rust' OR draft=true", false},
+	}
+
+	for _, tt := range tests {
+		if got := NewQuery(tt.expr).Match(doc); got != tt.match {
+			t.Errorf("NewQuery(%q).Match = %v; want %v", tt.expr, got, tt.match)
+		}
+	}
+}
+
+// TestQuery_MatchAt tests evaluating relative dates against a given time.
+func TestQuery_MatchAt(t *testing.T) {
+	doc := &Document{Format: YAML, Fields: map[string]interface{}{"date": "2024-01-01"}}
+	q := NewQuery("date < now - 1y")
+
+	if !q.MatchAt(doc, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected a match a year and a half later")
+	}
+	if q.MatchFieldsAt(doc.Fields, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected no match half a year later")
+	}
+}
@@ -0,0 +1,110 @@
+package frontmatter
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/internal"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
+)
+
+// Stats summarises a run.
+type Stats struct {
+	Processed int
+	Matched   int
+	// Updated is the number of files whose frontmatter changed.
+	Updated   int
+	LintFails int
+	LintFixed int
+}
+
+// FileResult is the outcome of processing a single content file.
+type FileResult struct {
+	// Path is the slash-separated path of the file within the source.
+	Path string
+	// HasFrontmatter is false for files without a frontmatter block.
+	HasFrontmatter bool
+	// Matched reports whether the file satisfied the configured condition.
+	Matched bool
+	// Changed reports whether Content differs from the original file.
+	Changed bool
+	// Content is the full updated document. It is only set when Changed is true.
+	Content []byte
+	// Extract maps each of Config.ExtractKeys set in the file to its value.
+	// It is nil unless keys are extracted and the file matched.
+	Extract map[string]interface{}
+}
+
+// Result holds the per-file results of a run in path order.
+type Result struct {
+	Files []FileResult
+	Stats Stats
+}
+
+// Runner applies a config.Config to every content file in Source.
+// It never prints, prompts or writes: the updated content of each
+// changed file is returned in the Result for the caller to persist.
+// Source may be any fs.FS, including the archives and git trees of package vfs.
+type Runner struct {
+	Config config.Config
+	Source fs.FS
+}
+
+// Run processes the content files of Source using Config.Jobs workers.
+// Like the CLI, it lists the files in Config.ContentDir (or the root when
+// it is empty) and the per-language Config.ContentDirs, with the content
+// extensions of Config.Site. Operations that look beyond one file, such as
+// Effective, AllTranslations and AddResources, see all of those files.
+// Untranslated is not supported, as the Result has no room for its report.
+func (r *Runner) Run() (*Result, error) {
+	cfg := r.Config
+	if cfg.Untranslated {
+		return nil, errors.New("the untranslated report is not supported by Runner")
+	}
+	if cfg.ContentDir == "" {
+		cfg.ContentDir = "."
+	}
+	paths, err := internal.ListContent(cfg, r.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	prepare, err := internal.FilePreparer(cfg, r.Source, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	err = internal.ProcessFiles(cfg.Jobs, paths, prepare, func(res internal.FileResult) error {
+		if res.Err != nil {
+			return &fs.PathError{Op: "process", Path: res.Path, Err: res.Err}
+		}
+		result.Stats.Processed += res.Stats.Processed
+		result.Stats.Matched += res.Stats.Matched
+		if res.Changed() {
+			result.Stats.Updated++
+		}
+		result.Stats.LintFails += res.Stats.LintFails
+		result.Stats.LintFixed += res.Stats.LintFixed
+
+		fr := FileResult{
+			Path:           path.Clean(res.Path),
+			HasFrontmatter: res.Delimiter != "",
+			Matched:        res.Matched,
+			Changed:        res.Changed(),
+		}
+		if fr.Changed {
+			fr.Content = res.Content()
+		}
+		if res.Extract != nil {
+			fr.Extract = res.Extract.Values
+		}
+		result.Files = append(result.Files, fr)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
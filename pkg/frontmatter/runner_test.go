@@ -0,0 +1,124 @@
+// Package frontmatter_test contains unit tests for the public frontmatter API.
+package frontmatter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/config"
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/site"
+)
+
+// TestRunner_Run tests running a config over an in-memory filesystem.
+func TestRunner_Run(t *testing.T) {
+	source := fstest.MapFS{
+		"content/a.md":      {Data: []byte("---\ntitle: A\ndraft: true\n---\nA\n")},
+		"content/b.md":      {Data: []byte("---\ntitle: B\ndraft: false\n---\nB\n")},
+		"content/c.md":      {Data: []byte("No frontmatter\n")},
+		"content/notes.txt": {Data: []byte("ignored")},
+	}
+
+	r := &Runner{
+		Config: config.Config{ContentDir: "content", SetField: "draft=false", Condition: "draft=true", Jobs: 2},
+		Source: source,
+	}
+	res, err := r.Run()
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+
+	if len(res.Files) != 3 {
+		t.Fatalf("expected 3 files, got %d", len(res.Files))
+	}
+	if res.Stats.Processed != 3 || res.Stats.Matched != 1 {
+		t.Errorf("unexpected stats: %+v", res.Stats)
+	}
+
+	a := res.Files[0]
+	if a.Path != "content/a.md" || !a.Matched || !a.Changed {
+		t.Errorf("unexpected result for a.md: %+v", a)
+	}
+	if !strings.Contains(string(a.Content), "draft: false") {
+		t.Errorf("expected updated content, got %q", a.Content)
+	}
+	if res.Files[1].Changed {
+		t.Errorf("b.md should not match the condition")
+	}
+	if res.Files[2].HasFrontmatter {
+		t.Errorf("c.md has no frontmatter")
+	}
+
+	// The source must be left untouched.
+	if string(source["content/a.md"].Data) != "---\ntitle: A\ndraft: true\n---\nA\n" {
+		t.Errorf("source was modified")
+	}
+}
+
+// TestRunner_Run_ContentFiles tests listing content like the CLI: with the
+// site's content extensions and from every content directory.
+func TestRunner_Run_ContentFiles(t *testing.T) {
+	source := fstest.MapFS{
+		"content/a.md":       {Data: []byte("---\ntitle: A\n---\n")},
+		"content/b.html":     {Data: []byte("---\ntitle: B\n---\n")},
+		"content/c.adoc":     {Data: []byte("---\ntitle: C\n---\n")},
+		"content-de/a.de.md": {Data: []byte("---\ntitle: A\n---\n")},
+	}
+	r := &Runner{
+		Config: config.Config{
+			ContentDir:  "content",
+			ContentDirs: []string{"content-de"},
+			Site: site.FromMap(map[string]interface{}{
+				"contentTypes": map[string]interface{}{"text/markdown": map[string]interface{}{}, "text/html": map[string]interface{}{}},
+			}),
+		},
+		Source: source,
+	}
+	res, err := r.Run()
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+
+	var paths []string
+	for _, f := range res.Files {
+		paths = append(paths, f.Path)
+	}
+	if want := []string{"content/a.md", "content/b.html", "content-de/a.de.md"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("Run processed %v; want %v", paths, want)
+	}
+}
+
+// TestRunner_Run_Effective tests evaluating conditions against values
+// cascaded from other files of Source.
+func TestRunner_Run_Effective(t *testing.T) {
+	source := fstest.MapFS{
+		"content/_index.md":      {Data: []byte("---\ntitle: Home\n---\n")},
+		"content/docs/_index.md": {Data: []byte("---\ntitle: Docs\ncascade:\n  draft: true\n---\n")},
+		"content/docs/a.md":      {Data: []byte("---\ntitle: A\n---\n")},
+		"content/blog/b.md":      {Data: []byte("---\ntitle: B\n---\n")},
+	}
+	r := &Runner{
+		Config: config.Config{ContentDir: "content", SetField: "reviewed=false", Condition: "draft=true", Effective: true},
+		Source: source,
+	}
+	res, err := r.Run()
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	var changed []string
+	for _, f := range res.Files {
+		if f.Changed {
+			changed = append(changed, f.Path)
+		}
+	}
+	// The _index file defining the cascade is not its target
+	if want := []string{"content/docs/a.md"}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("changed %v; want %v", changed, want)
+	}
+
+	r.Config.Untranslated = true
+	if _, err := r.Run(); err == nil {
+		t.Errorf("expected an error for Untranslated")
+	}
+}
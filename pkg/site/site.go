@@ -0,0 +1,436 @@
+// Package site reads the parts of a Hugo site configuration that drive the
+// toolbox defaults: content directories, languages, taxonomies and the
+// frontmatter date-field mappings.
+package site
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// configNames are the base names Hugo accepts for the root configuration file, in lookup order.
+var configNames = []string{"hugo", "config"}
+
+// configExts are the configuration file formats Hugo accepts, in lookup order.
+var configExts = []string{".toml", ".yaml", ".yml", ".json"}
+
+// defaultDateFields are Hugo's default frontmatter mappings for the date-valued page fields.
+var defaultDateFields = map[string][]string{
+	"date":        {"date", "publishDate", "lastmod"},
+	"publishDate": {"publishDate", "date"},
+	"lastmod":     {"lastmod", "date", "publishDate"},
+	"expiryDate":  {"expiryDate"},
+}
+
+// contentTypeExts maps Hugo content media types to the file extensions Hugo reads them from.
+var contentTypeExts = map[string][]string{
+	"text/markdown": {".md", ".markdown", ".mdown"},
+	"text/html":     {".html", ".htm"},
+	"text/asciidoc": {".adoc", ".asciidoc", ".ad"},
+	"text/org":      {".org"},
+	"text/pandoc":   {".pandoc", ".pdc"},
+	"text/rst":      {".rst"},
+}
+
+// Language is a configured site language.
+type Language struct {
+	ContentDir string
+	Weight     int
+}
+
+// Site holds the configuration of a Hugo site.
+type Site struct {
+	// ConfigFiles lists the files the configuration was read from.
+	ConfigFiles []string
+	// ContentDir is the default content directory.
+	ContentDir string
+	// DefaultContentLanguage is the language of content without a language suffix.
+	DefaultContentLanguage string
+	// Languages maps language codes to their settings.
+	Languages map[string]Language
+	// Taxonomies maps singular taxonomy names to their plural frontmatter keys.
+	Taxonomies map[string]string
+	// DateFields maps each date-valued page field to the frontmatter keys Hugo
+	// reads it from, in order. Special values such as ":filename" are omitted.
+	DateFields map[string][]string
+	// Permalinks maps sections to permalink patterns.
+	Permalinks map[string]string
+	// ContentExtensions lists the file extensions Hugo treats as content.
+	ContentExtensions []string
+	// ArchetypeDir is the directory holding the content archetypes.
+	ArchetypeDir string
+	// StaticDirs lists the directories holding static files.
+	StaticDirs []string
+	// TimeZone is the IANA time zone of dates without one, or "" for UTC.
+	TimeZone string
+	// Raw holds the merged configuration, with lower-cased keys.
+	Raw map[string]interface{}
+}
+
+// ErrNotFound is returned by Load when no site configuration exists.
+var ErrNotFound = errors.New("no Hugo site configuration found")
+
+// Default returns the configuration Hugo uses when nothing is configured.
+func Default() *Site {
+	s := &Site{
+		ContentDir:             "content",
+		DefaultContentLanguage: "en",
+		Languages:              map[string]Language{},
+		Taxonomies:             map[string]string{"tag": "tags", "category": "categories"},
+		DateFields:             map[string][]string{},
+		Permalinks:             map[string]string{},
+		ContentExtensions:      []string{".md"},
+		ArchetypeDir:           "archetypes",
+		StaticDirs:             []string{"static"},
+		Raw:                    map[string]interface{}{},
+	}
+	for field, keys := range defaultDateFields {
+		s.DateFields[field] = append([]string(nil), keys...)
+	}
+	return s
+}
+
+// Load reads the site configuration in dir of fsys: every file in
+// config/_default/ and the first of hugo.toml, hugo.yaml, hugo.yml,
+// hugo.json, config.toml, ..., with the root file taking precedence.
+// It returns ErrNotFound when there is none.
+func Load(fsys fs.FS, dir string) (*Site, error) {
+	merged := map[string]interface{}{}
+	var files []string
+
+	defaultDir := path.Join(dir, "config", "_default")
+	if entries, err := fs.ReadDir(fsys, defaultDir); err == nil {
+		for _, e := range entries {
+			if e.IsDir() || !isConfigExt(path.Ext(e.Name())) {
+				continue
+			}
+			name := path.Join(defaultDir, e.Name())
+			values, err := readConfigFile(fsys, name)
+			if err != nil {
+				return nil, err
+			}
+			base := strings.ToLower(strings.TrimSuffix(e.Name(), path.Ext(e.Name())))
+			if base == "hugo" || base == "config" {
+				mergeInto(merged, values)
+			} else {
+				// languages.toml, params.toml etc. configure the key they are named after
+				mergeInto(merged, map[string]interface{}{base: values})
+			}
+			files = append(files, name)
+		}
+	}
+
+	if name := findRootConfig(fsys, dir); name != "" {
+		values, err := readConfigFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		mergeInto(merged, values)
+		files = append(files, name)
+	}
+
+	if len(files) == 0 {
+		return nil, ErrNotFound
+	}
+	s := FromMap(merged)
+	s.ConfigFiles = files
+	return s, nil
+}
+
+// FromMap builds a Site from a decoded configuration, applying Hugo's defaults.
+func FromMap(values map[string]interface{}) *Site {
+	values = lowerKeys(values).(map[string]interface{})
+	s := Default()
+	s.Raw = values
+
+	if v, ok := values["contentdir"].(string); ok && v != "" {
+		s.ContentDir = v
+	}
+	if v, ok := values["archetypedir"].(string); ok && v != "" {
+		s.ArchetypeDir = v
+	}
+	if dirs := toStrings(values["staticdir"]); len(dirs) > 0 {
+		s.StaticDirs = dirs
+	}
+	if v, ok := values["timezone"].(string); ok {
+		s.TimeZone = v
+	}
+	if v, ok := values["defaultcontentlanguage"].(string); ok && v != "" {
+		s.DefaultContentLanguage = v
+	}
+
+	if langs, ok := values["languages"].(map[string]interface{}); ok {
+		for code, raw := range langs {
+			lang := Language{ContentDir: s.ContentDir}
+			if m, ok := raw.(map[string]interface{}); ok {
+				if v, ok := m["contentdir"].(string); ok && v != "" {
+					lang.ContentDir = v
+				}
+				lang.Weight = toInt(m["weight"])
+			}
+			s.Languages[code] = lang
+		}
+	}
+
+	if tax, ok := values["taxonomies"].(map[string]interface{}); ok {
+		s.Taxonomies = map[string]string{}
+		for singular, plural := range tax {
+			if p, ok := plural.(string); ok && p != "" {
+				s.Taxonomies[singular] = p
+			}
+		}
+	}
+
+	if fm, ok := values["frontmatter"].(map[string]interface{}); ok {
+		for field, raw := range fm {
+			name := canonicalDateField(field)
+			if name == "" {
+				continue
+			}
+			var keys []string
+			for _, key := range toStrings(raw) {
+				switch {
+				case strings.EqualFold(key, ":default"):
+					keys = append(keys, defaultDateFields[name]...)
+				case strings.HasPrefix(key, ":"):
+					// :filename, :fileModTime and :git are not frontmatter keys
+				default:
+					keys = append(keys, key)
+				}
+			}
+			s.DateFields[name] = dedupe(keys)
+		}
+	}
+
+	if perm, ok := values["permalinks"].(map[string]interface{}); ok {
+		for key, raw := range perm {
+			switch v := raw.(type) {
+			case string:
+				s.Permalinks[key] = v
+			case map[string]interface{}:
+				// Hugo 0.112+ groups patterns by kind: [permalinks.page] posts = "..."
+				if key != "page" {
+					continue
+				}
+				for section, pattern := range v {
+					if p, ok := pattern.(string); ok {
+						s.Permalinks[section] = p
+					}
+				}
+			}
+		}
+	}
+
+	if types, ok := values["contenttypes"].(map[string]interface{}); ok && len(types) > 0 {
+		var exts []string
+		for mediaType := range types {
+			exts = append(exts, contentTypeExts[mediaType]...)
+		}
+		if len(exts) > 0 {
+			sort.Strings(exts)
+			s.ContentExtensions = exts
+		}
+	}
+
+	return s
+}
+
+// LanguageCodes returns the configured languages ordered by weight, then code.
+// The default content language is returned when no languages are configured.
+func (s *Site) LanguageCodes() []string {
+	if len(s.Languages) == 0 {
+		return []string{s.DefaultContentLanguage}
+	}
+	var codes []string
+	for code := range s.Languages {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		wi, wj := s.Languages[codes[i]].Weight, s.Languages[codes[j]].Weight
+		if wi != wj {
+			return wi < wj
+		}
+		return codes[i] < codes[j]
+	})
+	return codes
+}
+
+// ContentDirs returns every distinct content directory of the site, the
+// default content directory first.
+func (s *Site) ContentDirs() []string {
+	dirs := []string{s.ContentDir}
+	for _, code := range s.LanguageCodes() {
+		if lang, ok := s.Languages[code]; ok {
+			dirs = append(dirs, lang.ContentDir)
+		}
+	}
+	return dedupe(dirs)
+}
+
+// TaxonomyKeys returns the plural frontmatter keys of the configured taxonomies, sorted.
+func (s *Site) TaxonomyKeys() []string {
+	var keys []string
+	for _, plural := range s.Taxonomies {
+		keys = append(keys, plural)
+	}
+	sort.Strings(keys)
+	return dedupe(keys)
+}
+
+// FieldOrder returns the order in which well-known fields are written to
+// YAML frontmatter: title, the date fields, draft, then the taxonomies.
+func (s *Site) FieldOrder() []string {
+	order := []string{"title"}
+	order = append(order, s.DateFields["date"]...)
+	order = append(order, "draft")
+	order = append(order, s.TaxonomyKeys()...)
+	return dedupe(order)
+}
+
+// findRootConfig returns the path of the root configuration file in dir, or "".
+func findRootConfig(fsys fs.FS, dir string) string {
+	for _, base := range configNames {
+		for _, ext := range configExts {
+			name := path.Join(dir, base+ext)
+			if info, err := fs.Stat(fsys, name); err == nil && !info.IsDir() {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// readConfigFile decodes a TOML, YAML or JSON configuration file.
+func readConfigFile(fsys fs.FS, name string) (map[string]interface{}, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]interface{}{}
+	switch path.Ext(name) {
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".json":
+		err = json.Unmarshal(data, &values)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %v", name, err)
+	}
+	return values, nil
+}
+
+// isConfigExt reports whether ext is a configuration file extension.
+func isConfigExt(ext string) bool {
+	for _, e := range configExts {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// canonicalDateField maps a [frontmatter] key to the page field it configures.
+func canonicalDateField(key string) string {
+	switch strings.ToLower(key) {
+	case "date":
+		return "date"
+	case "publishdate", "pubdate", "published":
+		return "publishDate"
+	case "lastmod", "modified":
+		return "lastmod"
+	case "expirydate", "unpublishdate":
+		return "expiryDate"
+	}
+	return ""
+}
+
+// mergeInto deep-merges src into dst, with src taking precedence.
+func mergeInto(dst, src map[string]interface{}) {
+	for k, v := range src {
+		k = strings.ToLower(k)
+		if sm, ok := v.(map[string]interface{}); ok {
+			dm, ok := dst[k].(map[string]interface{})
+			if !ok {
+				dm = map[string]interface{}{}
+				dst[k] = dm
+			}
+			mergeInto(dm, sm)
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+// lowerKeys returns v with all map keys lower-cased, as Hugo treats configuration keys case-insensitively.
+func lowerKeys(v interface{}) interface{} {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(m))
+		for k, val := range m {
+			out[strings.ToLower(k)] = lowerKeys(val)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(m))
+		for i, val := range m {
+			out[i] = lowerKeys(val)
+		}
+		return out
+	}
+	return v
+}
+
+// toStrings converts a string or list of strings to a slice.
+func toStrings(v interface{}) []string {
+	switch val := v.(type) {
+	case string:
+		return []string{val}
+	case []interface{}:
+		var out []string
+		for _, item := range val {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	case []string:
+		return val
+	}
+	return nil
+}
+
+// toInt converts a decoded numeric value to an int.
+func toInt(v interface{}) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case int64:
+		return int(n)
+	case float64:
+		return int(n)
+	}
+	return 0
+}
+
+// dedupe removes repeated items, keeping the first occurrence.
+func dedupe(items []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, item := range items {
+		if !seen[item] {
+			seen[item] = true
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,105 @@
+// Package site_test contains unit tests for the site package.
+package site
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Daviey/hugo-frontmatter-toolbox/pkg/vfs"
+)
+
+// TestLoad_RootFile tests reading a root hugo.toml.
+func TestLoad_RootFile(t *testing.T) {
+	fsys := vfs.NewMemFS(map[string][]byte{
+		"hugo.toml": []byte(`
+contentDir = "src/content"
+defaultContentLanguage = "de"
+archetypeDir = "src/archetypes"
+timeZone = "Europe/London"
+staticDir = ["static", "assets/static"]
+
+[taxonomies]
+tag = "tags"
+series = "series"
+
+[frontmatter]
+date = ["pubdate", ":default"]
+lastmod = [":git", "lastmod"]
+
+[permalinks]
+posts = "/:year/:slug/"
+`),
+	})
+
+	s, err := Load(fsys, ".")
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if s.ContentDir != "src/content" || s.DefaultContentLanguage != "de" || s.ArchetypeDir != "src/archetypes" || s.TimeZone != "Europe/London" ||
+		!reflect.DeepEqual(s.StaticDirs, []string{"static", "assets/static"}) {
+		t.Errorf("unexpected site: %+v", s)
+	}
+	if !reflect.DeepEqual(s.TaxonomyKeys(), []string{"series", "tags"}) {
+		t.Errorf("unexpected taxonomies: %v", s.TaxonomyKeys())
+	}
+	if !reflect.DeepEqual(s.DateFields["date"], []string{"pubdate", "date", "publishDate", "lastmod"}) {
+		t.Errorf("unexpected date mapping: %v", s.DateFields["date"])
+	}
+	if !reflect.DeepEqual(s.DateFields["lastmod"], []string{"lastmod"}) {
+		t.Errorf("unexpected lastmod mapping: %v", s.DateFields["lastmod"])
+	}
+	if s.Permalinks["posts"] != "/:year/:slug/" {
+		t.Errorf("unexpected permalinks: %v", s.Permalinks)
+	}
+}
+
+// TestLoad_ConfigDir tests merging config/_default with a root file.
+func TestLoad_ConfigDir(t *testing.T) {
+	fsys := vfs.NewMemFS(map[string][]byte{
+		"config/_default/hugo.yaml": []byte("contentDir: content\ntitle: Dir\n"),
+		"config/_default/languages.yaml": []byte(`
+en:
+  weight: 1
+  contentDir: content/en
+de:
+  weight: 2
+  contentDir: content/de
+`),
+		"config.json": []byte(`{"title": "Root"}`),
+	})
+
+	s, err := Load(fsys, ".")
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if len(s.ConfigFiles) != 3 {
+		t.Errorf("expected 3 config files, got %v", s.ConfigFiles)
+	}
+	if s.Raw["title"] != "Root" {
+		t.Errorf("root config should take precedence, got %v", s.Raw["title"])
+	}
+	if !reflect.DeepEqual(s.LanguageCodes(), []string{"en", "de"}) {
+		t.Errorf("unexpected languages: %v", s.LanguageCodes())
+	}
+	if !reflect.DeepEqual(s.ContentDirs(), []string{"content", "content/en", "content/de"}) {
+		t.Errorf("unexpected content dirs: %v", s.ContentDirs())
+	}
+}
+
+// TestLoad_NotFound tests that a missing configuration is reported.
+func TestLoad_NotFound(t *testing.T) {
+	if _, err := Load(vfs.NewMemFS(nil), "."); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+// TestFromMap_ContentTypes tests deriving content extensions from contentTypes.
+func TestFromMap_ContentTypes(t *testing.T) {
+	s := FromMap(map[string]interface{}{
+		"contentTypes": map[string]interface{}{"text/markdown": map[string]interface{}{}},
+	})
+	if !reflect.DeepEqual(s.ContentExtensions, []string{".markdown", ".md", ".mdown"}) {
+		t.Errorf("unexpected extensions: %v", s.ContentExtensions)
+	}
+}
@@ -0,0 +1,61 @@
+// Package vfs_test contains unit tests for the vfs package.
+package vfs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestZip tests loading content from a zip archive.
+func TestZip(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "site.zip")
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, _ := zw.Create("content/post.md")
+	_, _ = w.Write([]byte("---\ntitle: Zip\n---\n"))
+	_ = zw.Close()
+	_ = f.Close()
+
+	fsys, err := Open(name)
+	if err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+	data, err := fs.ReadFile(fsys, "content/post.md")
+	if err != nil || string(data) != "---\ntitle: Zip\n---\n" {
+		t.Errorf("ReadFile = %q, %v", data, err)
+	}
+}
+
+// TestTar tests loading content from a gzip-compressed tar archive.
+func TestTar(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "site.tar.gz")
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	body := []byte("+++\ntitle = 'Tar'\n+++\n")
+	_ = tw.WriteHeader(&tar.Header{Name: "./content/post.md", Mode: 0600, Size: int64(len(body)), Typeflag: tar.TypeReg})
+	_, _ = tw.Write(body)
+	_ = tw.Close()
+	_ = gz.Close()
+	_ = f.Close()
+
+	fsys, err := Open(name)
+	if err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+	data, err := fs.ReadFile(fsys, "content/post.md")
+	if err != nil || string(data) != string(body) {
+		t.Errorf("ReadFile = %q, %v", data, err)
+	}
+}
@@ -0,0 +1,170 @@
+package vfs
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// execCommand is a variable that holds the command execution function.
+// It defaults to exec.Command but can be overridden for testing purposes.
+var execCommand = exec.Command
+
+// gitBlob is a file of a git tree.
+type gitBlob struct {
+	object string
+	size   int64
+}
+
+// gitFS is a read-only git tree. Files are read from the repository when
+// they are opened, through one git cat-file process.
+type gitFS struct {
+	repo  string
+	blobs map[string]gitBlob
+
+	mu  sync.Mutex
+	cat *exec.Cmd
+	in  io.WriteCloser
+	out *bufio.Reader
+}
+
+// GitTree returns the tree of the git repository at repo as of rev as a
+// read-only FS. Paths are relative to repo, which may be a subdirectory of
+// the repository, and only its part of the tree is listed. File contents
+// are only loaded when a file is opened. The working copy is never touched.
+// The FS implements io.Closer to stop the git process reading files.
+func GitTree(repo, rev string) (FS, error) {
+	out, err := execCommand("git", "-C", repo, "rev-parse", "--show-prefix").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git rev-parse failed: %v", err)
+	}
+	prefix := strings.TrimSpace(string(out))
+	args := []string{"-C", repo, "ls-tree", "-r", "-z", "-l", "--full-tree", rev}
+	if prefix != "" {
+		args = append(args, "--", prefix)
+	}
+	out, err = execCommand("git", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git ls-tree %s failed: %v", rev, err)
+	}
+
+	g := &gitFS{repo: repo, blobs: map[string]gitBlob{}}
+	for _, entry := range strings.Split(string(out), "\x00") {
+		// <mode> SP <type> SP <object> SP <size> TAB <path>
+		meta, name, ok := strings.Cut(entry, "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 4 || fields[1] != "blob" {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected git ls-tree output: %q", entry)
+		}
+		g.blobs[strings.TrimPrefix(name, prefix)] = gitBlob{object: fields[2], size: size}
+	}
+	return g, nil
+}
+
+// WriteFile always fails with ErrReadOnly.
+func (g *gitFS) WriteFile(name string, _ []byte, _ fs.FileMode) error {
+	return &fs.PathError{Op: "write", Path: name, Err: ErrReadOnly}
+}
+
+// Open opens the named file or directory.
+func (g *gitFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if blob, ok := g.blobs[name]; ok {
+		data, err := g.read(blob)
+		if err != nil {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+		return &memFile{info: memInfo{name: path.Base(name), size: blob.size}, r: bytes.NewReader(data)}, nil
+	}
+	return openDir(name, func(visit func(file string, size int64)) {
+		for file, blob := range g.blobs {
+			visit(file, blob.size)
+		}
+	})
+}
+
+// Stat returns the FileInfo of the named file or directory without
+// reading the file.
+func (g *gitFS) Stat(name string) (fs.FileInfo, error) {
+	if blob, ok := g.blobs[name]; ok {
+		return memInfo{name: path.Base(name), size: blob.size}, nil
+	}
+	f, err := g.Open(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: errors.Unwrap(err)}
+	}
+	return f.Stat()
+}
+
+// read returns the contents of blob, starting git cat-file on first use.
+func (g *gitFS) read(blob gitBlob) ([]byte, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.cat == nil {
+		cmd := execCommand("git", "-C", g.repo, "cat-file", "--batch")
+		in, err := cmd.StdinPipe()
+		if err != nil {
+			return nil, err
+		}
+		out, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, fmt.Errorf("git cat-file failed: %v", err)
+		}
+		g.cat, g.in, g.out = cmd, in, bufio.NewReader(out)
+	}
+
+	if _, err := fmt.Fprintln(g.in, blob.object); err != nil {
+		return nil, fmt.Errorf("git cat-file failed: %v", err)
+	}
+	// <object> SP <type> SP <size> LF <contents> LF
+	header, err := g.out.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("reading from git cat-file: %v", err)
+	}
+	fields := strings.Fields(header)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("unexpected git cat-file output: %q", header)
+	}
+	size, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return nil, fmt.Errorf("unexpected git cat-file output: %q", header)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(g.out, data); err != nil {
+		return nil, fmt.Errorf("reading from git cat-file: %v", err)
+	}
+	if _, err := g.out.Discard(1); err != nil {
+		return nil, fmt.Errorf("reading from git cat-file: %v", err)
+	}
+	return data, nil
+}
+
+// Close stops the git cat-file process, if it was started.
+func (g *gitFS) Close() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.cat == nil {
+		return nil
+	}
+	_ = g.in.Close()
+	err := g.cat.Wait()
+	g.cat = nil
+	return err
+}
@@ -0,0 +1,111 @@
+// Package vfs_test contains unit tests for the vfs package.
+package vfs
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestGitTree tests reading content from an earlier git revision.
+func TestGitTree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	run := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com",
+			"GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	post := filepath.Join(repo, "content", "post.md")
+
+	run("init", "-q")
+	_ = os.MkdirAll(filepath.Dir(post), 0700)
+	_ = os.WriteFile(post, []byte("---\ndraft: true\n---\n"), 0600)
+	run("add", ".")
+	run("commit", "-q", "-m", "first")
+	_ = os.WriteFile(post, []byte("---\ndraft: false\n---\n"), 0600)
+	run("commit", "-q", "-am", "second")
+
+	fsys, err := GitTree(repo, "HEAD~1")
+	if err != nil {
+		t.Fatalf("GitTree error: %v", err)
+	}
+	data, err := fs.ReadFile(fsys, "content/post.md")
+	if err != nil || string(data) != "---\ndraft: true\n---\n" {
+		t.Errorf("ReadFile = %q, %v", data, err)
+	}
+	if err := fsys.WriteFile("content/post.md", nil, 0600); err == nil {
+		t.Errorf("expected git tree to be read-only")
+	}
+	if err := fsys.(io.Closer).Close(); err != nil {
+		t.Errorf("Close error: %v", err)
+	}
+}
+
+// TestGitTree_Subdirectory tests resolving paths against a subdirectory of
+// the repository, as when the tool runs from a site inside it.
+func TestGitTree_Subdirectory(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	run := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com",
+			"GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	files := map[string]string{
+		"README.md":                  "repo\n",
+		"site/hugo.toml":             "title = 'Site'\n",
+		"site/content/post.md":       "---\ntitle: Post\n---\n",
+		"site/content/photo.jpg":     "\xff\xd8binary",
+		"site/content/doc/_index.md": "---\ntitle: Docs\n---\n",
+	}
+	run("init", "-q")
+	for name, data := range files {
+		p := filepath.Join(repo, filepath.FromSlash(name))
+		_ = os.MkdirAll(filepath.Dir(p), 0700)
+		_ = os.WriteFile(p, []byte(data), 0600)
+	}
+	run("add", ".")
+	run("commit", "-q", "-m", "first")
+
+	fsys, err := GitTree(filepath.Join(repo, "site"), "HEAD")
+	if err != nil {
+		t.Fatalf("GitTree error: %v", err)
+	}
+	defer fsys.(io.Closer).Close()
+
+	var names []string
+	err = fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			names = append(names, p)
+		}
+		return err
+	})
+	want := []string{"content/doc/_index.md", "content/photo.jpg", "content/post.md", "hugo.toml"}
+	if err != nil || !reflect.DeepEqual(names, want) {
+		t.Errorf("WalkDir = %v, %v; want %v", names, err, want)
+	}
+	for _, name := range want {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil || string(data) != files["site/"+name] {
+			t.Errorf("ReadFile(%s) = %q, %v", name, data, err)
+		}
+	}
+	if info, err := fs.Stat(fsys, "content/photo.jpg"); err != nil || info.Size() != int64(len(files["site/content/photo.jpg"])) {
+		t.Errorf("Stat = %v, %v", info, err)
+	}
+}
@@ -0,0 +1,71 @@
+// Package vfs_test contains unit tests for the vfs package.
+package vfs
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+// TestMemFS tests MemFS against the io/fs conformance checks.
+func TestMemFS(t *testing.T) {
+	m := NewMemFS(map[string][]byte{
+		"content/posts/a.md": []byte("a"),
+		"content/b.md":       []byte("b"),
+		"hugo.toml":          []byte("title = 'x'"),
+	})
+	if err := fstest.TestFS(m, "content/posts/a.md", "content/b.md", "hugo.toml"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestMemFS_WriteFile tests writing and reading back a file.
+func TestMemFS_WriteFile(t *testing.T) {
+	m := NewMemFS(nil)
+	if err := m.WriteFile("content/new.md", []byte("new"), 0600); err != nil {
+		t.Fatalf("WriteFile error: %v", err)
+	}
+	data, err := fs.ReadFile(m, "content/new.md")
+	if err != nil || string(data) != "new" {
+		t.Errorf("ReadFile = %q, %v; want \"new\"", data, err)
+	}
+	if err := m.WriteFile("../escape.md", nil, 0600); err == nil {
+		t.Errorf("expected error for invalid path")
+	}
+}
+
+// TestMemFS_Remove tests deleting files and the directories they imply.
+func TestMemFS_Remove(t *testing.T) {
+	m := NewMemFS(map[string][]byte{"content/a/index.md": []byte("a")})
+	if err := Remove(m, "content/a/index.md"); err != nil {
+		t.Fatalf("Remove error: %v", err)
+	}
+	if _, err := fs.Stat(m, "content/a"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected the emptied directory to disappear, got %v", err)
+	}
+	if err := Remove(m, "content/a/index.md"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected ErrNotExist, got %v", err)
+	}
+}
+
+// TestReadOnly tests that ReadOnly rejects writes.
+func TestReadOnly(t *testing.T) {
+	ro := ReadOnly(NewMemFS(map[string][]byte{"a.md": []byte("a")}))
+	if err := ro.WriteFile("a.md", []byte("b"), 0600); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly, got %v", err)
+	}
+	if err := Remove(ro, "a.md"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly from Remove, got %v", err)
+	}
+}
+
+// TestOpen_Unsupported tests that unknown source specs are rejected.
+func TestOpen_Unsupported(t *testing.T) {
+	if _, err := Open("content.rar"); err == nil {
+		t.Errorf("expected error for unsupported source")
+	}
+	if fsys, err := Open(""); err != nil || fsys != (OS{}) {
+		t.Errorf("Open(\"\") = %v, %v; want OS{}", fsys, err)
+	}
+}
@@ -0,0 +1,108 @@
+// Package vfs defines the file systems the hugo-frontmatter-toolbox can read
+// content from and write changes back to: the host file system, an in-memory
+// tree, a zip or tar archive, or a git tree at a given revision.
+package vfs
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FS is a file system that content can be read from and written back to.
+type FS interface {
+	fs.FS
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+}
+
+// RemoveFS is an FS that files can also be deleted from.
+type RemoveFS interface {
+	FS
+	Remove(name string) error
+}
+
+// ErrReadOnly is returned when writing to a read-only file system.
+var ErrReadOnly = errors.New("read-only file system")
+
+// Remove deletes the named file from fsys. It fails with ErrReadOnly when
+// fsys does not support removing files.
+func Remove(fsys FS, name string) error {
+	if rfs, ok := fsys.(RemoveFS); ok {
+		return rfs.Remove(name)
+	}
+	return &fs.PathError{Op: "remove", Path: name, Err: ErrReadOnly}
+}
+
+// OS is the host file system. Unlike os.DirFS, names are host paths and may
+// be absolute or relative to the working directory.
+type OS struct{}
+
+// Open opens the named file.
+func (OS) Open(name string) (fs.File, error) {
+	// #nosec G304 - Callers walk a user-chosen content directory
+	return os.Open(name)
+}
+
+// Stat returns the FileInfo of the named file.
+func (OS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+// ReadFile reads the named file.
+func (OS) ReadFile(name string) ([]byte, error) {
+	// #nosec G304 - Callers walk a user-chosen content directory
+	return os.ReadFile(name)
+}
+
+// WriteFile writes data to the named file, creating its parent directories.
+func (OS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0750); err != nil {
+		return err
+	}
+	return os.WriteFile(name, data, perm)
+}
+
+// Remove deletes the named file or empty directory.
+func (OS) Remove(name string) error {
+	return os.Remove(name)
+}
+
+// readOnlyFS wraps an fs.FS and rejects writes.
+type readOnlyFS struct {
+	fs.FS
+}
+
+// WriteFile always fails with ErrReadOnly.
+func (readOnlyFS) WriteFile(name string, _ []byte, _ fs.FileMode) error {
+	return &fs.PathError{Op: "write", Path: name, Err: ErrReadOnly}
+}
+
+// ReadOnly returns an FS that reads from fsys and rejects every write.
+func ReadOnly(fsys fs.FS) FS {
+	return readOnlyFS{fsys}
+}
+
+// Open returns the file system described by spec:
+//
+//	""              the host file system
+//	"git:REV"       the tree of the git repository in the working directory at REV
+//	"*.zip"         a zip archive
+//	"*.tar", "*.tar.gz", "*.tgz"
+//	                a tar archive, optionally gzip-compressed
+//
+// Archives and git trees are read-only.
+func Open(spec string) (FS, error) {
+	switch {
+	case spec == "":
+		return OS{}, nil
+	case strings.HasPrefix(spec, "git:"):
+		return GitTree(".", strings.TrimPrefix(spec, "git:"))
+	case strings.HasSuffix(spec, ".zip"):
+		return Zip(spec)
+	case strings.HasSuffix(spec, ".tar"), strings.HasSuffix(spec, ".tar.gz"), strings.HasSuffix(spec, ".tgz"):
+		return Tar(spec)
+	}
+	return nil, errors.New("unsupported source: " + spec + " (expected git:REV, .zip, .tar, .tar.gz or .tgz)")
+}
@@ -29,9 +29,27 @@ A CLI tool for batch editing Hugo frontmatter (YAML, TOML, JSON).
 - 🧹 **Frontmatter linting** - Check for required or prohibited fields
 - 🔧 **Automatic fixes** - Auto-fix lint issues with ` + "`--fix`" + `
 - 🔍 **Diff visualization** - Preview changes with colorized diffs using ` + "`--dry-run`" + `
-- 📊 **Summary reporting** - Get concise execution summaries with ` + "`--report`" + `
+- 📊 **Summary reporting** - Get execution summaries with ` + "`--report`" + ` counting matched, changed, written, declined, unchanged and unparseable files, per-operation changes and elapsed time, or a JSON, Markdown or HTML report of every file's action, lint violations, skipped files and errors with ` + "`--report-format`" + ` and ` + "`--report-file`" + `
 - 🔀 **Git integration** - Automatically commit changes with ` + "`--gc`" + `
 - ✓ **Non-interactive mode** - Skip confirmation prompts with ` + "`--yes`" + ` or ` + "`-y`" + `
+- ⚡ **Parallel processing** - Process large sites concurrently with ` + "`--jobs`" + `, with output kept in path order
+- 🗂️ **Alternative sources** - Read content from zip/tar archives or a git revision with ` + "`--source`" + `
+- 🚰 **Filter mode** - Edit a single document from stdin to stdout with ` + "`--stdin`" + `
+- 🏗️ **Site-aware defaults** - Reads ` + "`hugo.toml`" + `/` + "`config/_default/`" + ` for content dirs, languages, taxonomies and date-field mappings
+- 🌐 **Multilingual awareness** - Groups translations, checks shared fields and reports untranslated pages
+- 🪜 **Cascade-aware** - Optionally evaluates conditions, lint and extraction against values cascaded from section ` + "`_index`" + ` files
+- 🧱 **Archetypes** - Creates content from ` + "`archetypes/`" + ` with ` + "`new`" + ` and lints pages for fields their archetype defines
+- 🔗 **URL checks** - Computes permalinks from paths, slugs, ` + "`url`" + ` and permalink patterns and reports duplicate URLs and conflicting aliases
+- 🗓️ **Publishing lifecycle** - ` + "`publish`" + `, ` + "`schedule`" + `, ` + "`expire`" + `, ` + "`archive`" + ` and ` + "`status`" + ` commands for drafts, publish and expiry dates
+- 📅 **Date normalisation** - ` + "`normalize-dates`" + ` rewrites dates in any supported format into one layout and time zone as native YAML/TOML dates
+- 🖼️ **Page bundle resources** - ` + "`resources`" + ` lists bundle files, reports ` + "`resources`" + ` globs matching nothing and missing ` + "`featured_image`" + `/` + "`images`" + ` files, and adds entries for uncovered images
+- 📊 **Spreadsheet round-trip** - ` + "`export`" + ` fields to CSV/TSV and ` + "`import`" + ` the edited file back, applying only changed cells with type conversion
+- 🔢 **Statistics** - ` + "`stats`" + ` counts pages, groups them by any field (with year/month buckets for dates) and lists distinct values, as text, CSV or JSON
+- 📋 **Field Inventory** - ` + "`fields`" + ` lists every key, including nested paths, with its types, file counts and samples, flags keys whose type drifts between files, and can infer a JSON Schema
+- 🔁 **Type Coercion** - ` + "`coerce`" + ` converts fields to list, bool, int, float, date or string across YAML, TOML and JSON, reporting values that cannot be converted instead of mangling them
+- 🧯 **Continue on error** - ` + "`--keep-going`" + ` records files whose frontmatter cannot be parsed or written, with line numbers, finishes the rest and exits non-zero with an error summary; ` + "`--fail-fast`" + ` (the default) stops at the first, and the last of the two given wins
+- 🆕 **Add Missing Frontmatter** - ` + "`init`" + ` finds content files without frontmatter and adds a YAML, TOML or JSON block, seeded from a template and with the title taken from the first heading and the date from git or the file time
+- 🔎 **Frontmatter Queries** - ` + "`query`" + ` evaluates jq or JSONPath-style expressions such as ` + "`resources[].params.credits`" + ` against each page, with ` + "`$file`" + `, ` + "`$section`" + ` and ` + "`$body`" + `, and prints the results per file
 
 ## Installation
 
@@ -58,7 +76,9 @@ go install github.com/Daviey/hugo-frontmatter-toolbox@latest
 You can use the ` + "`--if`" + ` flag to filter which markdown files to modify. The tool supports:
 
 - **Simple comparison**: ` + "`--if \"draft=true\"`" + `
-- **Date comparison**: ` + "`--if \"date<2022-01-01\"`" + `
+- **Date comparison**: ` + "`--if \"date<2022-01-01\"`" + `, on any date field such as ` + "`publishDate`" + `, ` + "`expiryDate`" + ` or ` + "`lastmod`" + `, with ` + "`<`" + `, ` + "`<=`" + `, ` + "`>`" + ` or ` + "`>=`" + `; numbers compare the same way, e.g. ` + "`--if \"weight>=10\"`" + `
+- **Relative dates**: ` + "`--if \"date < now-1y\"`" + `, ` + "`--if \"expiryDate <= today\"`" + ` or ` + "`--if \"publishDate > -90d\"`" + `; use ` + "`--now`" + ` for reproducible runs
+- **Recency**: ` + "`--if \"lastmod within 30d\"`" + `
 - **List field checks**: ` + "`--if \"tags contains 'draft'\"`" + `
 - **Boolean operators**: Use ` + "`AND`" + ` and ` + "`OR`" + ` to combine conditions
 
@@ -109,6 +129,24 @@ draft = true
 }
 ` + "```" + `
 
+### Using as a Go Library
+
+The ` + "`pkg/frontmatter`" + ` package exposes the same engine for use from Go programs:
+
+` + "```go" + `
+doc, err := frontmatter.Parse(data)
+if err == nil && frontmatter.NewQuery("draft=true").Match(doc) {
+	doc.Set("draft", false)
+	data, err = doc.Bytes()
+}
+
+r := &frontmatter.Runner{
+	Config: config.Config{SetField: "draft=false", Condition: "draft=true"},
+	Source: os.DirFS("content"),
+}
+res, err := r.Run() // res.Files holds the updated content of each changed file
+` + "```" + `
+
 ### Bulk Migration Scenarios
 
 #### Migrating from WordPress/Ghost/Jekyll
@@ -379,6 +417,151 @@ func generateExamples() string {
 			Description: "Extract all values of the 'draft' field:",
 			Command:     "--extract draft",
 		},
+		{
+			Title:       "Query an earlier revision",
+			Description: "Extract values from the content as it was at a git revision, without touching the working copy:",
+			Command:     "--source git:v1.0 --extract draft",
+		},
+		{
+			Title:       "Filter a single document",
+			Description: "Apply an edit to one document in a shell pipeline or editor integration, reading from stdin and writing to stdout:",
+			Command:     "--stdin --set draft=false < post.md > out.md",
+		},
+		{
+			Title:       "Translation consistency",
+			Description: "Check that every translation shares the same date and weight as the default-language page, and fix any that drift:",
+			Command:     "--lint --fix --shared \"date,weight,translationKey\"",
+		},
+		{
+			Title:       "Edit all translations",
+			Description: "Mark a page and all of its translations as draft when any of them is tagged beta:",
+			Command:     "--set draft=true --if \"tags contains 'beta'\" --all-translations",
+		},
+		{
+			Title:       "Untranslated pages",
+			Description: "List pages missing a translation in each configured language:",
+			Command:     "--untranslated",
+		},
+		{
+			Title:       "Match on cascaded values",
+			Description: "Select pages that are drafts because a section `_index.md` cascades `draft: true` to them; only the literal frontmatter is rewritten:",
+			Command:     "--set reviewed=false --if \"draft=true\" --effective",
+		},
+		{
+			Title:       "Create content from an archetype",
+			Description: "Create `content/posts/my-first-post.md` from `archetypes/posts.md` (or `archetypes/default.md`), filling in placeholders such as `{{ .Name }}` and `{{ .Date }}`:",
+			Command:     "new posts/my-first-post.md",
+		},
+		{
+			Title:       "Lint against archetypes",
+			Description: "Report the fields each page is missing compared to its section's archetype:",
+			Command:     "--lint --archetype",
+		},
+		{
+			Title:       "Check for URL collisions",
+			Description: "Report pages that share a URL, aliases declared twice or shadowing a page, and aliases of unpublished pages (exits 1 if any are found):",
+			Command:     "check-urls",
+		},
+		{
+			Title:       "Keep old URLs working when renaming slugs",
+			Description: "Normalise slugs and add each page's previous URL to its `aliases` so existing links redirect:",
+			Command:     "--set slug=getting-started --if \"title=Getting Started\" --add-aliases",
+		},
+		{
+			Title:       "Publish drafts",
+			Description: "Clear `draft` and set `publishDate` to now on pages that have none:",
+			Command:     "publish --if \"tags contains 'ready'\"",
+		},
+		{
+			Title:       "Schedule a page",
+			Description: "Set `publishDate` and clear `draft`:",
+			Command:     "schedule --at 2025-03-01T09:00:00Z --if \"title=Launch\"",
+		},
+		{
+			Title:       "Expire old content",
+			Description: "Set `expiryDate` on pages dated more than two years ago:",
+			Command:     "expire --older-than 2y",
+		},
+		{
+			Title:       "Archive pages",
+			Description: "Move pages into the `archive` section, adding their old URL to `aliases`; page bundles move with their resources:",
+			Command:     "archive --if \"tags contains 'legacy'\" --to archive",
+		},
+		{
+			Title:       "Publishing status",
+			Description: "List drafts, future-scheduled and expired pages as of a given date:",
+			Command:     "status --now 2025-01-01",
+		},
+		{
+			Title:       "Normalise dates",
+			Description: "Rewrite every date field as RFC 3339 in the London time zone, reporting values that cannot be parsed:",
+			Command:     "normalize-dates --layout rfc3339 --tz Europe/London",
+		},
+		{
+			Title:       "Check page bundle resources",
+			Description: "List bundle files, report broken `resources` entries and image references, and add entries for images that have none:",
+			Command:     "resources --add-missing",
+		},
+		{
+			Title:       "Extract several fields as a table",
+			Description: "Show one row per published post with a column per key; nested keys use dots, and `--extract-format` also accepts csv, json, jsonl and yaml:",
+			Command:     "--extract title,date,tags,params.author --extract-format table --if \"draft=false\"",
+		},
+		{
+			Title:       "Export fields to a spreadsheet",
+			Description: "Write the title, date and tags of every post to a CSV file keyed by file path:",
+			Command:     "export --fields title,date,tags --if \"draft=false\" -o posts.csv",
+		},
+		{
+			Title:       "Import spreadsheet edits",
+			Description: "Apply the cells changed in the edited file, with the usual diff, confirmation and optional commit:",
+			Command:     "import posts.csv --gc",
+		},
+		{
+			Title:       "Posts per year",
+			Description: "Count the published posts per year of their date:",
+			Command:     "stats --group-by date --bucket year --if \"draft=false\"",
+		},
+		{
+			Title:       "Tag frequency",
+			Description: "Show the 50 most used tags:",
+			Command:     "stats --group-by tags --limit 50",
+		},
+		{
+			Title:       "Inventory frontmatter fields",
+			Description: "List every key in use with its types and samples, and report keys whose type differs between files:",
+			Command:     "fields",
+		},
+		{
+			Title:       "Infer a JSON Schema",
+			Description: "Print a JSON Schema inferred from the blog posts:",
+			Command:     "fields --schema --if \"type=post\"",
+		},
+		{
+			Title:       "Repair inconsistent field types",
+			Description: "Turn `tags: go` into a list, `draft: \"true\"` into a boolean and `weight: \"5\"` into an integer:",
+			Command:     "coerce tags=list draft=bool weight=int --dry-run",
+		},
+		{
+			Title:       "Attach a report to a pull request",
+			Description: "Write a Markdown report of the lint run, with each file's action, violations, skipped files and errors; the format follows the file extension:",
+			Command:     "--lint --required title,date --dry-run --report-file report.md",
+		},
+		{
+			Title:       "Keep going past broken files",
+			Description: "Apply an edit site-wide even if some posts have malformed frontmatter, then list them with their line numbers:",
+			Command:     "--set draft=false --if \"draft=true\" --yes --keep-going",
+		},
+		{
+			Title:       "Add Frontmatter to Files Lacking It",
+			Description: "Add a TOML block to the content files without one, seeded from an archetype, with titles from the first heading and dates from git history",
+			Command:     "init --format toml --template archetypes/default.md --dry-run",
+		},
+		{
+			Title:       "Query Nested Frontmatter",
+			Description: "List the pages with a resource whose credits are missing or empty, using a jq-like expression",
+			Command:     "query --files 'any(resources[]; .params.credits == null or .params.credits == \"\")'",
+		},
 	}
 
 	var result strings.Builder